@@ -12,6 +12,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	"time-tracker/internal/app"
 	"time-tracker/internal/tracker"
@@ -55,26 +56,85 @@ func main() {
 	}
 	l.Info("up migrations OK")
 
+	personInfo, err := newPersonInfoProvider(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	repo := tracker.NewRepository(db)
-	service := tracker.NewService(repo, cfg.APIURL)
+	service := tracker.NewService(repo, personInfo, cfg.ExclusiveTracking)
 	handler := tracker.NewHandler(service)
 
-	mw := tracker.NewMiddleware(l)
+	mw := tracker.NewMiddleware(l, cfg.AdminToken)
+
+	workerCtx, stopWorkers := context.WithCancel(context.WithValue(ctx, tracker.LoggerCtxKey{}, l))
+	defer stopWorkers()
+
+	go service.RunEnrichment(workerCtx, tracker.EnrichmentConfig{
+		Interval:      cfg.EnrichmentInterval,
+		BatchSize:     cfg.EnrichmentBatchSize,
+		MaxAttempts:   cfg.EnrichmentMaxAttempts,
+		RetryDelay:    cfg.EnrichmentRetryDelay,
+		MaxRetryDelay: cfg.EnrichmentMaxRetryDelay,
+		Lease:         cfg.EnrichmentLease,
+	})
+
+	go service.RunReaper(workerCtx, tracker.ReaperConfig{
+		Interval:  cfg.ReaperInterval,
+		BatchSize: cfg.ReaperBatchSize,
+		MaxOpen:   cfg.WorkMaxOpenDuration,
+	})
 
 	router := http.NewServeMux()
 
 	router.HandleFunc("POST /users", handler.CreateUser)
+	router.HandleFunc("POST /users/import", handler.ImportUsers)
 	router.HandleFunc("GET /users", handler.Users)
 	router.HandleFunc("PATCH /users", handler.UpdateUser)
+	router.HandleFunc("GET /users/{user_id}", handler.UserByID)
+	router.HandleFunc("PATCH /users/{user_id}", handler.UpdateUserByID)
 	router.HandleFunc("DELETE /users/{user_id}", handler.DeleteUser)
+	router.HandleFunc("POST /users/{user_id}/restore", handler.RestoreUser)
+	router.HandleFunc("GET /users/{user_id}/history", handler.UserRevisions)
+	router.HandleFunc("POST /users/{user_id}/enrichment/retry", handler.RetryEnrichment)
 	router.HandleFunc("GET /users/{user_id}/report", handler.TaskSpendTimesByUser)
+	router.HandleFunc("GET /users/{user_id}/work/active", handler.UserActiveWork)
+
+	router.HandleFunc("GET /person-info/status", handler.PersonInfoStatus)
 
+	router.HandleFunc("POST /clients", handler.CreateClient)
+	router.HandleFunc("GET /clients", handler.Clients)
+	router.HandleFunc("GET /clients/{client_id}", handler.ClientByID)
+	router.HandleFunc("PATCH /clients/{client_id}", handler.UpdateClient)
+	router.HandleFunc("DELETE /clients/{client_id}", handler.DeleteClient)
+
+	router.HandleFunc("POST /projects", handler.CreateProject)
+	router.HandleFunc("GET /projects", handler.Projects)
+	router.HandleFunc("GET /projects/{project_id}", handler.ProjectByID)
+	router.HandleFunc("PATCH /projects/{project_id}", handler.UpdateProject)
+	router.HandleFunc("DELETE /projects/{project_id}", handler.DeleteProject)
+
+	router.HandleFunc("POST /tasks", handler.CreateTask)
+	router.HandleFunc("GET /tasks", handler.Tasks)
+	router.HandleFunc("GET /tasks/{task_id}", handler.TaskByID)
+	router.HandleFunc("PATCH /tasks/{task_id}", handler.UpdateTask)
+	router.HandleFunc("DELETE /tasks/{task_id}", handler.DeleteTask)
+
+	router.HandleFunc("GET /work/active", handler.ActiveWork)
 	router.HandleFunc("POST /work/start", handler.StartWork)
 	router.HandleFunc("POST /work/finish", handler.FinishWork)
+	router.HandleFunc("POST /work/switch", handler.SwitchWork)
+	router.HandleFunc("POST /work/pause", handler.PauseWork)
+	router.HandleFunc("POST /work/resume", handler.ResumeWork)
+	router.HandleFunc("POST /work/entries", handler.CreateWorkEntry)
+	router.HandleFunc("GET /work/entries", handler.WorkEntries)
+	router.HandleFunc("GET /work/entries/{entry_id}", handler.WorkEntryByID)
+	router.HandleFunc("PATCH /work/entries/{entry_id}", handler.UpdateWorkEntry)
+	router.HandleFunc("DELETE /work/entries/{entry_id}", handler.DeleteWorkEntry)
 
 	server := &http.Server{
 		Addr:              fmt.Sprintf(":%d", cfg.Port),
-		Handler:           mw.Log(router),
+		Handler:           mw.Log(mw.Auth(router)),
 		ReadTimeout:       time.Second * 3,
 		ReadHeaderTimeout: time.Second,
 	}
@@ -92,6 +152,8 @@ func main() {
 	signal.Notify(c, syscall.SIGTERM, syscall.SIGKILL)
 	<-c
 
+	stopWorkers()
+
 	err = server.Shutdown(ctx)
 	if err != nil {
 		log.Println("shutdown http server:", err)
@@ -120,3 +182,23 @@ func upMigrations(dsn string) error {
 
 	return nil
 }
+
+func newPersonInfoProvider(cfg app.Config) (tracker.PersonInfoProvider, error) {
+	switch cfg.PersonInfoProvider {
+	case "http":
+		retry := tracker.RetryPolicy{
+			MaxAttempts: cfg.PersonInfoMaxAttempts,
+			BaseDelay:   cfg.PersonInfoRetryBaseDelay,
+			MaxDelay:    cfg.PersonInfoRetryMaxDelay,
+		}
+		breaker := tracker.NewCircuitBreaker(cfg.PersonInfoBreakerThreshold, cfg.PersonInfoBreakerTimeout)
+
+		return tracker.NewHTTPPersonInfoProvider(cfg.APIURL, cfg.PersonInfoTimeout, retry, breaker), nil
+	case "static":
+		return tracker.NewStaticPersonInfoProvider(cfg.PersonInfoFixturePath)
+	case "none":
+		return tracker.NonePersonInfoProvider{}, nil
+	default:
+		return nil, fmt.Errorf("unknown person info provider: %q", cfg.PersonInfoProvider)
+	}
+}
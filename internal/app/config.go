@@ -1,6 +1,9 @@
 package app
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/caarlos0/env/v7"
 	"github.com/joho/godotenv"
 )
@@ -9,6 +12,34 @@ type Config struct {
 	Port        int    `env:"PORT"`
 	PostgresDSN string `env:"POSTGRES_DSN"`
 	APIURL      string `env:"API_URL"`
+	AdminToken  string `env:"ADMIN_TOKEN"`
+
+	// PersonInfoProvider is one of "http", "static" or "none".
+	PersonInfoProvider    string `env:"PERSON_INFO_PROVIDER" envDefault:"http"`
+	PersonInfoFixturePath string `env:"PERSON_INFO_FIXTURE_PATH"`
+
+	PersonInfoTimeout          time.Duration `env:"PERSON_INFO_TIMEOUT" envDefault:"5s"`
+	PersonInfoMaxAttempts      int           `env:"PERSON_INFO_MAX_ATTEMPTS" envDefault:"3"`
+	PersonInfoRetryBaseDelay   time.Duration `env:"PERSON_INFO_RETRY_BASE_DELAY" envDefault:"200ms"`
+	PersonInfoRetryMaxDelay    time.Duration `env:"PERSON_INFO_RETRY_MAX_DELAY" envDefault:"2s"`
+	PersonInfoBreakerThreshold int           `env:"PERSON_INFO_BREAKER_THRESHOLD" envDefault:"5"`
+	PersonInfoBreakerTimeout   time.Duration `env:"PERSON_INFO_BREAKER_TIMEOUT" envDefault:"30s"`
+
+	// ExclusiveTracking limits users to one running session at a time.
+	ExclusiveTracking bool `env:"EXCLUSIVE_TRACKING" envDefault:"false"`
+
+	ReaperInterval  time.Duration `env:"REAPER_INTERVAL" envDefault:"5m"`
+	ReaperBatchSize int           `env:"REAPER_BATCH_SIZE" envDefault:"100"`
+	// WorkMaxOpenDuration is how long a session may run before it's auto-closed.
+	WorkMaxOpenDuration time.Duration `env:"WORK_MAX_OPEN_DURATION" envDefault:"12h"`
+
+	EnrichmentInterval    time.Duration `env:"ENRICHMENT_INTERVAL" envDefault:"10s"`
+	EnrichmentBatchSize   int           `env:"ENRICHMENT_BATCH_SIZE" envDefault:"10"`
+	EnrichmentMaxAttempts int           `env:"ENRICHMENT_MAX_ATTEMPTS" envDefault:"5"`
+	EnrichmentRetryDelay  time.Duration `env:"ENRICHMENT_RETRY_DELAY" envDefault:"1m"`
+	// EnrichmentMaxRetryDelay caps the retry delay, which doubles with every failed attempt.
+	EnrichmentMaxRetryDelay time.Duration `env:"ENRICHMENT_MAX_RETRY_DELAY" envDefault:"1h"`
+	EnrichmentLease         time.Duration `env:"ENRICHMENT_LEASE" envDefault:"5m"`
 }
 
 func NewConfig(envPath string) (c Config, err error) {
@@ -22,5 +53,35 @@ func NewConfig(envPath string) (c Config, err error) {
 		return c, err
 	}
 
+	err = c.validate()
+	if err != nil {
+		return c, err
+	}
+
 	return c, nil
 }
+
+func (c Config) validate() error {
+	positive := []struct {
+		name  string
+		value int64
+	}{
+		{"REAPER_INTERVAL", int64(c.ReaperInterval)},
+		{"REAPER_BATCH_SIZE", int64(c.ReaperBatchSize)},
+		{"WORK_MAX_OPEN_DURATION", int64(c.WorkMaxOpenDuration)},
+		{"ENRICHMENT_INTERVAL", int64(c.EnrichmentInterval)},
+		{"ENRICHMENT_BATCH_SIZE", int64(c.EnrichmentBatchSize)},
+		{"ENRICHMENT_MAX_ATTEMPTS", int64(c.EnrichmentMaxAttempts)},
+		{"ENRICHMENT_RETRY_DELAY", int64(c.EnrichmentRetryDelay)},
+		{"ENRICHMENT_MAX_RETRY_DELAY", int64(c.EnrichmentMaxRetryDelay)},
+		{"ENRICHMENT_LEASE", int64(c.EnrichmentLease)},
+	}
+
+	for _, p := range positive {
+		if p.value <= 0 {
+			return fmt.Errorf("%s must be positive", p.name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package tracker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	allow := func(want error) func(*testing.T, *CircuitBreaker) {
+		return func(t *testing.T, b *CircuitBreaker) {
+			err := b.Allow()
+			if !errors.Is(err, want) {
+				t.Fatalf("Allow() = %v, want %v", err, want)
+			}
+		}
+	}
+	success := func(_ *testing.T, b *CircuitBreaker) { b.Success() }
+	failure := func(_ *testing.T, b *CircuitBreaker) { b.Failure() }
+	cancel := func(_ *testing.T, b *CircuitBreaker) { b.Cancel() }
+	// expire moves the opening back so the open timeout has passed
+	expire := func(_ *testing.T, b *CircuitBreaker) { b.openedAt = b.openedAt.Add(-time.Hour) }
+
+	tests := []struct {
+		name         string
+		steps        []func(*testing.T, *CircuitBreaker)
+		wantState    string
+		wantFailures int
+	}{
+		{
+			name:      "closed allows calls",
+			steps:     []func(*testing.T, *CircuitBreaker){allow(nil), success},
+			wantState: CircuitClosed,
+		},
+		{
+			name:         "failures below threshold keep it closed",
+			steps:        []func(*testing.T, *CircuitBreaker){failure, failure, allow(nil)},
+			wantState:    CircuitClosed,
+			wantFailures: 2,
+		},
+		{
+			name:         "success resets failures",
+			steps:        []func(*testing.T, *CircuitBreaker){failure, failure, success, failure, allow(nil)},
+			wantState:    CircuitClosed,
+			wantFailures: 1,
+		},
+		{
+			name:         "threshold opens",
+			steps:        []func(*testing.T, *CircuitBreaker){failure, failure, failure, allow(ErrCircuitOpen)},
+			wantState:    CircuitOpen,
+			wantFailures: 3,
+		},
+		{
+			name:         "open timeout lets a probe through",
+			steps:        []func(*testing.T, *CircuitBreaker){failure, failure, failure, expire, allow(nil)},
+			wantState:    CircuitHalfOpen,
+			wantFailures: 3,
+		},
+		{
+			name:         "half-open allows a single probe",
+			steps:        []func(*testing.T, *CircuitBreaker){failure, failure, failure, expire, allow(nil), allow(ErrCircuitOpen)},
+			wantState:    CircuitHalfOpen,
+			wantFailures: 3,
+		},
+		{
+			name:      "half-open success closes",
+			steps:     []func(*testing.T, *CircuitBreaker){failure, failure, failure, expire, allow(nil), success, allow(nil)},
+			wantState: CircuitClosed,
+		},
+		{
+			name:         "half-open failure reopens",
+			steps:        []func(*testing.T, *CircuitBreaker){failure, failure, failure, expire, allow(nil), failure, allow(ErrCircuitOpen)},
+			wantState:    CircuitOpen,
+			wantFailures: 4,
+		},
+		{
+			name:         "cancel releases the half-open probe",
+			steps:        []func(*testing.T, *CircuitBreaker){failure, failure, failure, expire, allow(nil), cancel, allow(nil)},
+			wantState:    CircuitHalfOpen,
+			wantFailures: 3,
+		},
+		{
+			name:         "cancel in closed changes nothing",
+			steps:        []func(*testing.T, *CircuitBreaker){failure, cancel, allow(nil)},
+			wantState:    CircuitClosed,
+			wantFailures: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewCircuitBreaker(3, time.Minute)
+
+			for _, step := range tt.steps {
+				step(t, b)
+			}
+
+			status := b.Status()
+			if status.State != tt.wantState {
+				t.Errorf("state = %q, want %q", status.State, tt.wantState)
+			}
+			if status.ConsecutiveFailures != tt.wantFailures {
+				t.Errorf("consecutive failures = %d, want %d", status.ConsecutiveFailures, tt.wantFailures)
+			}
+			if (status.OpenedAt == nil) != (tt.wantState == CircuitClosed) {
+				t.Errorf("opened at = %v in state %q", status.OpenedAt, status.State)
+			}
+		})
+	}
+}
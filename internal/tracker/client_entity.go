@@ -0,0 +1,21 @@
+package tracker
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type Client struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateClient struct {
+	Name string `json:"name"`
+}
+
+type UpdateClient struct {
+	Name *string `json:"name"`
+}
@@ -0,0 +1,246 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeDB is an in-memory stand-in for the statements on users and
+// user_revisions the service tests go through, statements are told apart by
+// their SQL and anything else fails the test.
+type fakeDB struct {
+	t         *testing.T
+	users     map[uuid.UUID]User
+	revisions []UserRevision
+	// claimedUntil is the enrichment_next_at set by a claim or a failed attempt.
+	claimedUntil map[uuid.UUID]time.Time
+}
+
+func newFakeDB(t *testing.T) *fakeDB {
+	return &fakeDB{t: t, users: make(map[uuid.UUID]User), claimedUntil: make(map[uuid.UUID]time.Time)}
+}
+
+func newTestService(t *testing.T, db *fakeDB, personInfo PersonInfoProvider) (*Service, context.Context) {
+	l := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.WithValue(context.Background(), LoggerCtxKey{}, l)
+
+	return NewService(&Repository{db: db}, personInfo, false), ctx
+}
+
+func (db *fakeDB) Begin(context.Context) (pgx.Tx, error) {
+	return fakeTx{db: db}, nil
+}
+
+func (db *fakeDB) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	switch {
+	case strings.Contains(sql, "INSERT INTO users "):
+		u := User{
+			ID:               args[0].(uuid.UUID),
+			PassportSeries:   args[1].(int),
+			PassportNumber:   args[2].(int),
+			Surname:          args[3].(string),
+			Name:             args[4].(string),
+			Patronymic:       args[5].(string),
+			Address:          args[6].(string),
+			TimeZone:         "UTC",
+			EnrichmentStatus: args[7].(string),
+			Version:          1,
+			CreatedAt:        args[9].(time.Time),
+		}
+		db.users[u.ID] = u
+		return pgconn.NewCommandTag("INSERT 0 1"), nil
+	case strings.Contains(sql, "SET surname = $1, name = $2, patronymic = $3, address = $4,"):
+		u, ok := db.users[args[4].(uuid.UUID)]
+		if !ok || u.Version != args[5].(int) || u.DeletedAt != nil {
+			return pgconn.NewCommandTag("UPDATE 0"), nil
+		}
+		u.Surname, u.Name, u.Patronymic, u.Address = args[0].(string), args[1].(string), args[2].(string), args[3].(string)
+		u.EnrichmentStatus = EnrichmentDone
+		u.Version++
+		db.users[u.ID] = u
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	case strings.Contains(sql, "SET enrichment_status = CASE WHEN $2::TIMESTAMPTZ ISNULL THEN 'failed' ELSE 'pending' END,"):
+		u, ok := db.users[args[2].(uuid.UUID)]
+		if !ok {
+			return pgconn.NewCommandTag("UPDATE 0"), nil
+		}
+		u.EnrichmentStatus, u.EnrichmentError = EnrichmentFailed, args[0].(string)
+		delete(db.claimedUntil, u.ID)
+		if nextAt := args[1].(*time.Time); nextAt != nil {
+			u.EnrichmentStatus = EnrichmentPending
+			db.claimedUntil[u.ID] = *nextAt
+		}
+		db.users[u.ID] = u
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	case strings.HasPrefix(sql, "UPDATE users SET "):
+		return db.updateUser(sql, args), nil
+	case strings.Contains(sql, "INSERT INTO user_revisions "):
+		db.revisions = append(db.revisions, UserRevision{
+			ID:        args[0].(uuid.UUID),
+			UserID:    args[1].(uuid.UUID),
+			Action:    args[2].(string),
+			Changes:   args[3].(map[string]FieldChange),
+			Actor:     args[4].(string),
+			CreatedAt: args[5].(time.Time),
+		})
+		return pgconn.NewCommandTag("INSERT 0 1"), nil
+	}
+
+	db.t.Fatalf("fake db: unexpected exec: %s", sql)
+	return pgconn.CommandTag{}, nil
+}
+
+// updateUser runs the UPDATE built by Repository.UpdateUser, the user ID is
+// its last argument.
+func (db *fakeDB) updateUser(sql string, args []any) pgconn.CommandTag {
+	u, ok := db.users[args[len(args)-1].(uuid.UUID)]
+	if !ok || u.DeletedAt != nil {
+		return pgconn.NewCommandTag("UPDATE 0")
+	}
+
+	set, _, _ := strings.Cut(strings.TrimPrefix(sql, "UPDATE users SET "), " WHERE ")
+	for _, assignment := range strings.Split(set, ", ") {
+		col, expr, _ := strings.Cut(assignment, " = ")
+
+		var v any = strings.Trim(expr, "'")
+		if n, ok := strings.CutPrefix(expr, "$"); ok {
+			i, _ := strconv.Atoi(n)
+			v = args[i-1]
+		}
+
+		switch col {
+		case "surname":
+			u.Surname = v.(string)
+		case "name":
+			u.Name = v.(string)
+		case "patronymic":
+			u.Patronymic = v.(string)
+		case "address":
+			u.Address = v.(string)
+		case "time_zone":
+			u.TimeZone = v.(string)
+		case "enrichment_status":
+			u.EnrichmentStatus = v.(string)
+		case "enrichment_error":
+			u.EnrichmentError = v.(string)
+		case "enrichment_next_at":
+			delete(db.claimedUntil, u.ID)
+		case "version":
+			u.Version++
+		default:
+			db.t.Fatalf("fake db: unexpected user column: %s", col)
+		}
+	}
+
+	db.users[u.ID] = u
+	return pgconn.NewCommandTag("UPDATE 1")
+}
+
+func (db *fakeDB) Query(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if strings.Contains(sql, "RETURNING id, passport_series, passport_number, enrichment_attempts, version") {
+		now, leaseEnd := args[0].(time.Time), args[1].(time.Time)
+
+		var rows fakeRows
+		for _, u := range db.users {
+			if u.EnrichmentStatus != EnrichmentPending || u.DeletedAt != nil || db.claimedUntil[u.ID].After(now) {
+				continue
+			}
+			db.claimedUntil[u.ID] = leaseEnd
+			rows.values = append(rows.values, []any{u.ID, u.PassportSeries, u.PassportNumber, 0, u.Version})
+		}
+		return &rows, nil
+	}
+
+	db.t.Fatalf("fake db: unexpected query: %s", sql)
+	return nil, nil
+}
+
+func (db *fakeDB) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	if strings.Contains(sql, "FROM users WHERE id = $1 AND deleted_at ISNULL") {
+		u, ok := db.users[args[0].(uuid.UUID)]
+		if !ok || u.DeletedAt != nil {
+			return fakeRow{err: pgx.ErrNoRows}
+		}
+		return fakeRow{values: []any{
+			u.ID, u.PassportSeries, u.PassportNumber, u.Surname, u.Name, u.Patronymic, u.Address, u.TimeZone,
+			u.EnrichmentStatus, u.EnrichmentError, u.Version, u.CreatedAt,
+		}}
+	}
+
+	db.t.Fatalf("fake db: unexpected query row: %s", sql)
+	return fakeRow{}
+}
+
+// fakeTx runs the statements of a transaction right away, Rollback doesn't
+// undo them.
+type fakeTx struct {
+	pgx.Tx
+	db *fakeDB
+}
+
+func (tx fakeTx) Begin(ctx context.Context) (pgx.Tx, error) { return tx.db.Begin(ctx) }
+func (tx fakeTx) Commit(context.Context) error              { return nil }
+func (tx fakeTx) Rollback(context.Context) error            { return nil }
+
+func (tx fakeTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return tx.db.Exec(ctx, sql, args...)
+}
+
+func (tx fakeTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return tx.db.Query(ctx, sql, args...)
+}
+
+func (tx fakeTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return tx.db.QueryRow(ctx, sql, args...)
+}
+
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	if len(dest) != len(r.values) {
+		return fmt.Errorf("fake row: scan %d values into %d destinations", len(r.values), len(dest))
+	}
+
+	for i, v := range r.values {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(v))
+	}
+
+	return nil
+}
+
+type fakeRows struct {
+	pgx.Rows
+	values [][]any
+	row    fakeRow
+}
+
+func (r *fakeRows) Next() bool {
+	if len(r.values) == 0 {
+		return false
+	}
+
+	r.row, r.values = fakeRow{values: r.values[0]}, r.values[1:]
+	return true
+}
+
+func (r *fakeRows) Scan(dest ...any) error { return r.row.Scan(dest...) }
+func (r *fakeRows) Err() error             { return nil }
+func (r *fakeRows) Close()                 {}
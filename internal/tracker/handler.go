@@ -1,11 +1,18 @@
 package tracker
 
 import (
+	"encoding/base64"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"math"
+	"mime"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -30,13 +37,16 @@ type PassportNumber struct {
 // CreateUser godoc
 //
 //	@Summary		Create a new user
-//	@Description	Create a new user with passport number
+//	@Description	Create a new user with passport number, person info is filled in asynchronously
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
 //	@Param			passportNumber	body		PassportNumber	true	"Passport number in format '1234 567890'"
-//	@Success		200				{object}	User
+//	@Success		201				{object}	User
+//	@Header			201				{string}	Location	"URL of the created user"
+//	@Header			201				{string}	ETag		"Version of the user for If-Match"
 //	@Failure		400				{string}	string	"Invalid input"
+//	@Failure		409				{object}	UserExistsResponse
 //	@Failure		500				{string}	string	"Internal error"
 //	@Router			/users [post]
 func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
@@ -51,71 +61,2240 @@ func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	parts := strings.Split(req.PassportNumber, " ")
+	passportSeries, passportNumber, err := parsePassportNumber(req.PassportNumber)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	if len(parts) != 2 {
-		http.Error(w, "passport number mast has format '1234 567890'", http.StatusBadRequest)
+	user, err := h.s.CreateUser(ctx, passportSeries, passportNumber)
+	if err != nil {
+		l.Error("create user", "error", err)
+		if errors.Is(err, ErrAlreadyExists) {
+			writeUserExists(w, err)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", userURL(user.ID))
+	w.Header().Set("ETag", userETag(user))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(user)
+	if err != nil {
+		l.Error("encode user", "error", err)
 		return
 	}
+}
+
+type UserExistsResponse struct {
+	Error      string     `json:"error"`
+	ExistingID *uuid.UUID `json:"existing_id,omitempty"`
+}
+
+func writeUserExists(w http.ResponseWriter, err error) {
+	resp := UserExistsResponse{Error: err.Error()}
+
+	var existsErr *UserExistsError
+	if errors.As(err, &existsErr) && !existsErr.ID.IsNil() {
+		resp.ExistingID = &existsErr.ID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func parsePassportNumber(s string) (series int, number int, err error) {
+	parts := strings.Split(s, " ")
+
+	if len(parts) != 2 {
+		return 0, 0, errors.New("passport number mast has format '1234 567890'")
+	}
 
 	if len(parts[0]) != 4 {
-		http.Error(w, "passport series must contains 4 signs", http.StatusBadRequest)
+		return 0, 0, errors.New("passport series must contains 4 signs")
+	}
+
+	series, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, errors.New("passport series must be a integer")
+	}
+
+	if len(parts[1]) != 6 {
+		return 0, 0, errors.New("passport number must contains 6 signs")
+	}
+
+	number, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, errors.New("passport number must be a integer")
+	}
+
+	return series, number, nil
+}
+
+const maxImportSize = 10 << 20
+
+type ImportUserRow struct {
+	PassportNumber string `json:"passportNumber"`
+	Surname        string `json:"surname"`
+	Name           string `json:"name"`
+	Patronymic     string `json:"patronymic"`
+	Address        string `json:"address"`
+}
+
+// ImportUsers godoc
+//
+//	@Summary		Import users
+//	@Description	Bulk create users from CSV (header row with passport_number and optional surname, name, patronymic, address columns), NDJSON rows or a JSON array.
+//	@Description	Users with pre-filled surname and name are not enriched.
+//	@Tags			users
+//	@Accept			text/csv
+//	@Accept			application/x-ndjson
+//	@Accept			json
+//	@Produce		json
+//	@Param			rows	body		ImportUserRow	true	"CSV file, NDJSON rows or a JSON array of rows"
+//	@Success		200		{object}	ImportUsersReport
+//	@Failure		400		{string}	string	"Invalid input"
+//	@Failure		413		{string}	string	"Import too large"
+//	@Failure		415		{string}	string	"Unsupported content type"
+//	@Router			/users/import [post]
+func (h *Handler) ImportUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
 		return
 	}
 
-	passportSeries, err := strconv.Atoi(parts[0])
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	var rows []ImportUserRow
+
+	switch mediaType {
+	case "text/csv":
+		rows, err = parseImportCSV(body)
+	case "application/x-ndjson", "application/jsonl":
+		rows, err = parseImportNDJSON(body)
+	case "application/json":
+		rows, err = parseImportJSON(body)
+	default:
+		http.Error(w, "content type must be text/csv, application/x-ndjson or application/json", http.StatusUnsupportedMediaType)
+		return
+	}
 	if err != nil {
-		http.Error(w, "passport series must be a integer", http.StatusBadRequest)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if len(parts[1]) != 6 {
-		http.Error(w, "passport number must contains 6 signs", http.StatusBadRequest)
+	var report ImportUsersReport
+	var users []ImportUser
+
+	for i, row := range rows {
+		rowNum := i + 1
+
+		series, number, err := parsePassportNumber(strings.TrimSpace(row.PassportNumber))
+		if err != nil {
+			report.Rows = append(report.Rows, ImportUserResult{Row: rowNum, Error: err.Error()})
+			continue
+		}
+
+		if (row.Surname == "") != (row.Name == "") {
+			report.Rows = append(report.Rows, ImportUserResult{Row: rowNum, Error: "surname and name must be set together"})
+			continue
+		}
+
+		users = append(users, ImportUser{
+			Row:            rowNum,
+			PassportSeries: series,
+			PassportNumber: number,
+			Surname:        row.Surname,
+			Name:           row.Name,
+			Patronymic:     row.Patronymic,
+			Address:        row.Address,
+		})
+	}
+
+	l.Info("import users", "rows", len(rows), "valid", len(users))
+	report.Rows = append(report.Rows, h.s.ImportUsers(ctx, users)...)
+
+	slices.SortFunc(report.Rows, func(a, b ImportUserResult) int {
+		return a.Row - b.Row
+	})
+
+	for _, row := range report.Rows {
+		if row.Error != "" {
+			report.Failed++
+		} else {
+			report.Created++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(report)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func parseImportCSV(r io.Reader) ([]ImportUserRow, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, col := range header {
+		col = strings.ToLower(strings.TrimSpace(col))
+		switch col {
+		case "passport_number", "surname", "name", "patronymic", "address":
+			columns[col] = i
+		default:
+			return nil, fmt.Errorf("unknown column %q", col)
+		}
+	}
+
+	if _, ok := columns["passport_number"]; !ok {
+		return nil, errors.New("passport_number column is required")
+	}
+
+	get := func(record []string, col string) string {
+		i, ok := columns[col]
+		if !ok {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []ImportUserRow
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, ImportUserRow{
+			PassportNumber: get(record, "passport_number"),
+			Surname:        get(record, "surname"),
+			Name:           get(record, "name"),
+			Patronymic:     get(record, "patronymic"),
+			Address:        get(record, "address"),
+		})
+	}
+
+	return rows, nil
+}
+
+func parseImportNDJSON(r io.Reader) ([]ImportUserRow, error) {
+	dec := json.NewDecoder(r)
+
+	var rows []ImportUserRow
+
+	for {
+		var row ImportUserRow
+
+		err := dec.Decode(&row)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", len(rows)+1, err)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func parseImportJSON(r io.Reader) ([]ImportUserRow, error) {
+	dec := json.NewDecoder(r)
+
+	var rows []ImportUserRow
+
+	err := dec.Decode(&rows)
+	if err != nil {
+		return nil, fmt.Errorf("rows must be a JSON array: %w", err)
+	}
+
+	_, err = dec.Token()
+	if !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected data after the JSON array")
+	}
+
+	return rows, nil
+}
+
+// UserByID godoc
+//
+//	@Summary		Get a user
+//	@Description	Get a user by ID with the enrichment status
+//	@Tags			users
+//	@Produce		json
+//	@Param			user_id	path		string	true	"User ID"
+//	@Success		200		{object}	User
+//	@Header			200		{string}	ETag	"Version of the user for If-Match"
+//	@Failure		400		{string}	string	"Invalid user ID"
+//	@Failure		404		{string}	string	"User not found"
+//	@Failure		500		{string}	string	"Internal error"
+//	@Router			/users/{user_id} [get]
+func (h *Handler) UserByID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	id, err := uuid.FromString(r.PathValue("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.s.UserByID(ctx, id)
+	if err != nil {
+		l.Error("get user by ID", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", userETag(user))
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// RetryEnrichment godoc
+//
+//	@Summary		Retry user enrichment
+//	@Description	Schedule a failed user enrichment for another attempt
+//	@Tags			users
+//	@Produce		json
+//	@Param			user_id	path		string	true	"User ID"
+//	@Success		200		{object}	User
+//	@Failure		400		{string}	string	"Invalid user ID"
+//	@Failure		404		{string}	string	"User not found"
+//	@Failure		409		{string}	string	"Enrichment not failed"
+//	@Failure		500		{string}	string	"Internal error"
+//	@Router			/users/{user_id}/enrichment/retry [post]
+func (h *Handler) RetryEnrichment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	id, err := uuid.FromString(r.PathValue("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.s.RetryEnrichment(ctx, id)
+	if err != nil {
+		l.Error("retry enrichment", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrEnrichmentNotFailed) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// PersonInfoStatus godoc
+//
+//	@Summary		Get person info API status
+//	@Description	Get the circuit breaker state of the external person info API
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{object}	CircuitBreakerStatus
+//	@Failure		404	{string}	string	"Person info provider has no circuit breaker"
+//	@Router			/person-info/status [get]
+func (h *Handler) PersonInfoStatus(w http.ResponseWriter, r *http.Request) {
+	status, ok := h.s.PersonInfoStatus()
+	if !ok {
+		http.Error(w, "person info provider has no circuit breaker", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// UpdateUserByID godoc
+//
+//	@Summary		Update an existing user
+//	@Description	Update user details. If-Match must hold the ETag of the user from GET or the previous PATCH. Setting the surname, name, patronymic or address ends a pending enrichment.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			user_id		path		string		true	"User ID"
+//	@Param			user		body		UpdateUser	true	"Fields to update, id is ignored"
+//	@Param			If-Match	header		string		true	"ETag of the user being updated"
+//	@Param			X-Actor		header		string		false	"Who makes the change, tagged unverified in user history without the admin token"
+//	@Success		200			{object}	User
+//	@Header			200			{string}	ETag	"Version of the updated user"
+//	@Failure		400			{string}	string	"Invalid input or malformed If-Match"
+//	@Failure		404			{string}	string	"User not found"
+//	@Failure		409			{object}	UserExistsResponse
+//	@Failure		412			{string}	string	"User was changed since it was read or If-Match has no version of it"
+//	@Failure		428			{string}	string	"If-Match header is required"
+//	@Failure		500			{string}	string	"Internal error"
+//	@Router			/users/{user_id} [patch]
+func (h *Handler) UpdateUserByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.FromString(r.PathValue("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var updUser UpdateUser
+
+	err = json.NewDecoder(r.Body).Decode(&updUser)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	updUser.ID = id
+
+	h.updateUser(w, r, updUser)
+}
+
+// UpdateUser godoc
+//
+//	@Summary		Update an existing user
+//	@Description	Deprecated: use PATCH /users/{user_id}. Update user details by the ID in the body.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			user		body		UpdateUser	true	"User to update"
+//	@Param			If-Match	header		string		true	"ETag of the user being updated"
+//	@Param			X-Actor		header		string		false	"Who makes the change, tagged unverified in user history without the admin token"
+//	@Success		200			{object}	User
+//	@Header			200			{string}	ETag		"Version of the updated user"
+//	@Header			200			{string}	Deprecation	"Always true"
+//	@Header			200			{string}	Link		"Successor route, rel=successor-version"
+//	@Failure		400			{string}	string	"Invalid input or malformed If-Match"
+//	@Failure		404			{string}	string	"User not found"
+//	@Failure		409			{object}	UserExistsResponse
+//	@Failure		412			{string}	string	"User was changed since it was read or If-Match has no version of it"
+//	@Failure		428			{string}	string	"If-Match header is required"
+//	@Failure		500			{string}	string	"Internal error"
+//	@Deprecated
+//	@Router			/users [patch]
+func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	var updUser UpdateUser
+
+	err := json.NewDecoder(r.Body).Decode(&updUser)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Deprecation", "true")
+	w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", userURL(updUser.ID)))
+
+	h.updateUser(w, r, updUser)
+}
+
+func (h *Handler) updateUser(w http.ResponseWriter, r *http.Request, updUser UpdateUser) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	if updUser.TimeZone != nil {
+		_, err := loadTimeZone(*updUser.TimeZone)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	versions, err := parseIfMatch(r.Header.Get("If-Match"))
+	if errors.Is(err, errIfMatchMissing) {
+		http.Error(w, err.Error(), http.StatusPreconditionRequired)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.s.UpdateUser(ctx, updUser, versions)
+	if err != nil {
+		l.Error("update user", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrAlreadyExists) {
+			writeUserExists(w, err)
+			return
+		}
+		if errors.Is(err, ErrVersionMismatch) {
+			http.Error(w, err.Error(), http.StatusPreconditionFailed)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", userETag(user))
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// parseWeekday parses an English day name like "monday" or "Sun".
+func parseWeekday(s string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := d.String()
+		if strings.EqualFold(s, name) || strings.EqualFold(s, name[:3]) {
+			return d, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown weekday %q", s)
+}
+
+// loadTimeZone loads an IANA time zone, the server's "Local" is not accepted.
+func loadTimeZone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+
+	return time.LoadLocation(name)
+}
+
+func userURL(id uuid.UUID) string {
+	return "/users/" + id.String()
+}
+
+func userETag(u User) string {
+	return strconv.Quote(strconv.Itoa(u.Version))
+}
+
+var errIfMatchMissing = errors.New("If-Match header is required")
+
+// parseIfMatch returns the user versions listed in If-Match, "*" matches any
+// version and gives nil. Weak and unknown ETags never match a user and are
+// skipped, so a list of only such ETags gives an empty slice.
+func parseIfMatch(h string) ([]int, error) {
+	h = strings.TrimSpace(h)
+
+	switch {
+	case h == "":
+		return nil, errIfMatchMissing
+	case h == "*":
+		return nil, nil
+	}
+
+	versions := []int{}
+	tags := 0
+
+	for rest := h; ; {
+		// empty list elements are allowed
+		rest = strings.TrimLeft(rest, " \t,")
+		if rest == "" {
+			break
+		}
+
+		weak := strings.HasPrefix(rest, "W/")
+		if weak {
+			rest = rest[len("W/"):]
+		}
+
+		if !strings.HasPrefix(rest, `"`) {
+			return nil, fmt.Errorf("If-Match must be * or a list of ETags: %s", h)
+		}
+
+		end := strings.IndexByte(rest[1:], '"')
+		if end < 0 {
+			return nil, fmt.Errorf("If-Match has an unterminated ETag: %s", h)
+		}
+
+		tag := rest[1 : end+1]
+		rest = strings.TrimLeft(rest[end+2:], " \t")
+		tags++
+
+		if rest != "" && rest[0] != ',' {
+			return nil, fmt.Errorf("If-Match ETags must be separated by commas: %s", h)
+		}
+
+		version, err := strconv.Atoi(tag)
+		if !weak && err == nil && version > 0 && strconv.Itoa(version) == tag {
+			versions = append(versions, version)
+		}
+	}
+
+	if tags == 0 {
+		return nil, fmt.Errorf("If-Match must be * or a list of ETags: %s", h)
+	}
+
+	return versions, nil
+}
+
+// DeleteUser godoc
+//
+//	@Summary		Delete a user
+//	@Description	Delete a user by ID. With purge=true the user and their work hours are removed permanently, this requires the 'Authorization: Bearer <admin token>' and X-Actor headers.
+//	@Tags			users
+//	@Produce		json
+//	@Param			user_id	path		string	true	"User ID"
+//	@Param			purge	query		bool	false	"Remove the user permanently"
+//	@Param			X-Actor	header		string	false	"Who makes the change, tagged unverified in user history without the admin token"
+//	@Success		200		{string}	string	"User deleted"
+//	@Failure		400		{string}	string	"Invalid user ID or purge without X-Actor"
+//	@Failure		403		{string}	string	"Purge requires admin token"
+//	@Failure		404		{string}	string	"User not found"
+//	@Failure		500		{string}	string	"Internal error"
+//	@Router			/users/{user_id} [delete]
+func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	id, err := uuid.FromString(r.PathValue("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	purge := false
+
+	purgeParam := r.URL.Query().Get("purge")
+	if purgeParam != "" {
+		purge, err = strconv.ParseBool(purgeParam)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if purge {
+		if !isAdmin(ctx) {
+			http.Error(w, "purge requires admin token", http.StatusForbidden)
+			return
+		}
+
+		// the purge removes the user history, the log is the only trace of who did it
+		if actorName(ctx) == "" {
+			http.Error(w, "purge requires the X-Actor header", http.StatusBadRequest)
+			return
+		}
+
+		err = h.s.PurgeUser(ctx, id)
+	} else {
+		err = h.s.DeleteUser(ctx, id)
+	}
+	if err != nil {
+		l.Error("delete user", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// RestoreUser godoc
+//
+//	@Summary		Restore a deleted user
+//	@Description	Restore a soft-deleted user by ID
+//	@Tags			users
+//	@Produce		json
+//	@Param			user_id	path		string	true	"User ID"
+//	@Param			X-Actor	header		string	false	"Who makes the change, tagged unverified in user history without the admin token"
+//	@Success		200		{object}	User
+//	@Failure		400		{string}	string	"Invalid user ID"
+//	@Failure		404		{string}	string	"User not found"
+//	@Failure		409		{string}	string	"User not deleted or another user with the same passport exists"
+//	@Failure		500		{string}	string	"Internal error"
+//	@Router			/users/{user_id}/restore [post]
+func (h *Handler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	id, err := uuid.FromString(r.PathValue("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.s.RestoreUser(ctx, id)
+	if err != nil {
+		l.Error("restore user", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrNotDeleted) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrAlreadyExists) {
+			writeUserExists(w, err)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// UserRevisions godoc
+//
+//	@Summary		Get user history
+//	@Description	Get changes made to a user, newest first
+//	@Tags			users
+//	@Produce		json
+//	@Param			user_id	path		string	true	"User ID"
+//	@Success		200		{object}	[]UserRevision
+//	@Failure		400		{string}	string	"Invalid user ID"
+//	@Failure		404		{string}	string	"User not found"
+//	@Failure		500		{string}	string	"Internal error"
+//	@Router			/users/{user_id}/history [get]
+func (h *Handler) UserRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	id, err := uuid.FromString(r.PathValue("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := h.s.UserRevisions(ctx, id)
+	if err != nil {
+		l.Error("get user revisions", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(revisions)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+type StartWorkRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+	TaskID uuid.UUID `json:"task_id"`
+}
+
+// StartWork godoc
+//
+//	@Summary		Start work on a task
+//	@Description	Start work on a task for a user, with exclusive tracking the running session of the user is finished
+//	@Tags			work
+//	@Accept			json
+//	@Produce		json
+//	@Param			startWorkRequest	body		StartWorkRequest	true	"Start work request"
+//	@Success		201					{object}	WorkHours
+//	@Header			201					{string}	Location			"URL of the work entry"
+//	@Failure		400					{string}	string				"Invalid input"
+//	@Failure		404					{string}	string				"User or task not found"
+//	@Failure		409					{string}	string				"Task closed or work already started"
+//	@Failure		500					{string}	string				"Internal error"
+//	@Router			/work/start [post]
+func (h *Handler) StartWork(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	var req StartWorkRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	wh, err := h.s.StartWork(ctx, req.UserID, req.TaskID)
+	if err != nil {
+		l.Error("start work", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrTaskClosed) || errors.Is(err, ErrWorkAlreadyStarted) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", workEntryURL(wh.ID))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(wh)
+	if err != nil {
+		l.Error("encode work entry", "error", err)
+		return
+	}
+}
+
+type FinishWorkRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+	TaskID uuid.UUID `json:"task_id"`
+}
+
+// FinishWork godoc
+//
+//	@Summary		Finish work on a task
+//	@Description	Finish work on a task for a user, a paused session is resumed first and pauses aren't counted
+//	@Tags			work
+//	@Accept			json
+//	@Produce		json
+//	@Param			finishWorkRequest	body		FinishWorkRequest	true	"Finish work request"
+//	@Success		200					{object}	WorkHours
+//	@Failure		400					{string}	string				"Invalid input"
+//	@Failure		404					{string}	string				"Task not found"
+//	@Failure		500					{string}	string				"Internal error"
+//	@Router			/work/finish [post]
+func (h *Handler) FinishWork(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	var req FinishWorkRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	wh, err := h.s.FinishWork(ctx, req.UserID, req.TaskID)
+	if err != nil {
+		l.Error("finish work", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(wh)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func workEntryURL(id uuid.UUID) string {
+	return "/work/entries/" + id.String()
+}
+
+type SwitchWorkRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+	TaskID uuid.UUID `json:"task_id"`
+}
+
+// SwitchWork godoc
+//
+//	@Summary		Switch work to another task
+//	@Description	Finish the running sessions of a user and start work on a task in one transaction
+//	@Tags			work
+//	@Accept			json
+//	@Produce		json
+//	@Param			switchWorkRequest	body		SwitchWorkRequest	true	"Switch work request"
+//	@Success		200					{object}	SwitchWork
+//	@Failure		400					{string}	string				"Invalid input"
+//	@Failure		404					{string}	string				"User or task not found"
+//	@Failure		409					{string}	string				"Task closed or work on it already started"
+//	@Failure		500					{string}	string				"Internal error"
+//	@Router			/work/switch [post]
+func (h *Handler) SwitchWork(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	var req SwitchWorkRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sw, err := h.s.SwitchWork(ctx, req.UserID, req.TaskID)
+	if err != nil {
+		l.Error("switch work", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrTaskClosed) || errors.Is(err, ErrWorkAlreadyStarted) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(sw)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+type PauseWorkRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+	TaskID uuid.UUID `json:"task_id"`
+}
+
+// PauseWork godoc
+//
+//	@Summary		Pause work on a task
+//	@Description	Pause the running session of a user on a task, paused time isn't counted in spend_time_sec
+//	@Tags			work
+//	@Accept			json
+//	@Produce		json
+//	@Param			pauseWorkRequest	body		PauseWorkRequest	true	"Pause work request"
+//	@Success		200					{string}	string				"Work paused"
+//	@Failure		400					{string}	string				"Invalid input"
+//	@Failure		404					{string}	string				"No running work"
+//	@Failure		409					{string}	string				"Work already paused"
+//	@Failure		500					{string}	string				"Internal error"
+//	@Router			/work/pause [post]
+func (h *Handler) PauseWork(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	var req PauseWorkRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.s.PauseWork(ctx, req.UserID, req.TaskID)
+	if err != nil {
+		l.Error("pause work", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrWorkPaused) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+type ResumeWorkRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+	TaskID uuid.UUID `json:"task_id"`
+}
+
+// ResumeWork godoc
+//
+//	@Summary		Resume work on a task
+//	@Description	Resume the paused session of a user on a task
+//	@Tags			work
+//	@Accept			json
+//	@Produce		json
+//	@Param			resumeWorkRequest	body		ResumeWorkRequest	true	"Resume work request"
+//	@Success		200					{string}	string				"Work resumed"
+//	@Failure		400					{string}	string				"Invalid input"
+//	@Failure		404					{string}	string				"No running work"
+//	@Failure		409					{string}	string				"Work not paused"
+//	@Failure		500					{string}	string				"Internal error"
+//	@Router			/work/resume [post]
+func (h *Handler) ResumeWork(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	var req ResumeWorkRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.s.ResumeWork(ctx, req.UserID, req.TaskID)
+	if err != nil {
+		l.Error("resume work", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrWorkNotPaused) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// CreateWorkEntry godoc
+//
+//	@Summary		Log a past work interval
+//	@Description	Create a finished work entry with explicit start and finish, it must not overlap other entries of the user or be in the future
+//	@Tags			work
+//	@Accept			json
+//	@Produce		json
+//	@Param			entry	body		CreateWorkEntry	true	"Work entry to create"
+//	@Success		201		{object}	WorkHours
+//	@Header			201		{string}	Location	"URL of the work entry"
+//	@Failure		400		{string}	string	"Invalid input"
+//	@Failure		404		{string}	string	"User or task not found"
+//	@Failure		409		{string}	string	"Task closed or entry overlaps another entry"
+//	@Failure		500		{string}	string	"Internal error"
+//	@Router			/work/entries [post]
+func (h *Handler) CreateWorkEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	var req CreateWorkEntry
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.UserID.IsNil() || req.TaskID.IsNil() {
+		http.Error(w, "work entry user_id and task_id must be set", http.StatusBadRequest)
+		return
+	}
+
+	if req.StartedAt.IsZero() || req.FinishedAt.IsZero() {
+		http.Error(w, "work entry started_at and finished_at must be set", http.StatusBadRequest)
+		return
+	}
+
+	wh, err := h.s.CreateWorkEntry(ctx, req)
+	if err != nil {
+		l.Error("create work entry", "error", err)
+		if errors.Is(err, ErrInvalidWorkEntry) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrTaskClosed) || errors.Is(err, ErrWorkOverlap) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", workEntryURL(wh.ID))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(wh)
+	if err != nil {
+		l.Error("encode work entry", "error", err)
+		return
+	}
+}
+
+const (
+	defaultWorkEntriesPerPage = 50
+	maxWorkEntriesPerPage     = 500
+	maxWorkEntriesPage        = math.MaxInt32
+)
+
+// WorkEntries godoc
+//
+//	@Summary		Get work entries
+//	@Description	Get individual work sessions with optional filters, newest first.
+//	@Description	The total number of matching entries is returned in the X-Total-Count header.
+//	@Tags			work
+//	@Produce		json
+//	@Param			page			query		int		false	"Page number, at most 2147483647"
+//	@Param			per_page		query		int		false	"Number of entries per page, at most 500"
+//	@Param			user_id			query		string	false	"User ID"
+//	@Param			task_id			query		string	false	"Task ID"
+//	@Param			from			query		string	false	"Entries running after this RFC 3339 time"
+//	@Param			to				query		string	false	"Entries started before this RFC 3339 time"
+//	@Param			status			query		string	false	"'open' or 'closed'"
+//	@Param			min_duration	query		string	false	"Minimum duration like '90m', running entries are compared by elapsed time"
+//	@Param			auto_closed		query		bool	false	"Only entries closed by the reaper, or only not"
+//	@Success		200				{object}	[]WorkHours
+//	@Header			200				{integer}	X-Total-Count	"Total number of matching entries"
+//	@Header			200				{string}	Link			"Link to the next page, rel=next"
+//	@Failure		400				{string}	string	"Invalid input"
+//	@Failure		500				{string}	string	"Internal error"
+//	@Router			/work/entries [get]
+func (h *Handler) WorkEntries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	page, err := parseWorkEntryPage(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parseWorkEntryFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.s.WorkEntries(ctx, page, filter)
+	if err != nil {
+		l.Error("get work entries", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(entries.Total))
+
+	if page.Page*page.PerPage < entries.Total {
+		next := *r.URL
+		query := next.Query()
+		query.Set("page", strconv.Itoa(page.Page+1))
+		next.RawQuery = query.Encode()
+
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(entries.Entries)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// parseWorkEntryPage reads page and per_page, page is bounded so that the
+// offset of any page fits in an int.
+func parseWorkEntryPage(v url.Values) (WorkEntryPage, error) {
+	page := WorkEntryPage{
+		Page:    1,
+		PerPage: defaultWorkEntriesPerPage,
+	}
+
+	var err error
+
+	if s := v.Get("page"); s != "" {
+		page.Page, err = strconv.Atoi(s)
+		if err != nil {
+			return WorkEntryPage{}, err
+		}
+
+		if page.Page < 1 {
+			return WorkEntryPage{}, errors.New("page must be positive")
+		}
+		if page.Page > maxWorkEntriesPage {
+			return WorkEntryPage{}, fmt.Errorf("page must be at most %d", maxWorkEntriesPage)
+		}
+	}
+
+	if s := v.Get("per_page"); s != "" {
+		page.PerPage, err = strconv.Atoi(s)
+		if err != nil {
+			return WorkEntryPage{}, err
+		}
+
+		if page.PerPage < 1 {
+			return WorkEntryPage{}, errors.New("per_page must be positive")
+		}
+
+		page.PerPage = min(page.PerPage, maxWorkEntriesPerPage)
+	}
+
+	return page, nil
+}
+
+func parseWorkEntryFilter(v url.Values) (f WorkEntryFilter, err error) {
+	userIDParam := v.Get("user_id")
+	if userIDParam != "" {
+		userID, err := uuid.FromString(userIDParam)
+		if err != nil {
+			return WorkEntryFilter{}, err
+		}
+		f.UserID = &userID
+	}
+
+	taskIDParam := v.Get("task_id")
+	if taskIDParam != "" {
+		taskID, err := uuid.FromString(taskIDParam)
+		if err != nil {
+			return WorkEntryFilter{}, err
+		}
+		f.TaskID = &taskID
+	}
+
+	fromParam := v.Get("from")
+	if fromParam != "" {
+		from, err := time.Parse(time.RFC3339, fromParam)
+		if err != nil {
+			return WorkEntryFilter{}, err
+		}
+		f.From = &from
+	}
+
+	toParam := v.Get("to")
+	if toParam != "" {
+		to, err := time.Parse(time.RFC3339, toParam)
+		if err != nil {
+			return WorkEntryFilter{}, err
+		}
+		f.To = &to
+	}
+
+	switch status := v.Get("status"); status {
+	case "":
+	case "open", "closed":
+		open := status == "open"
+		f.Open = &open
+	default:
+		return WorkEntryFilter{}, fmt.Errorf("unknown status %q", status)
+	}
+
+	minDurationParam := v.Get("min_duration")
+	if minDurationParam != "" {
+		minDuration, err := time.ParseDuration(minDurationParam)
+		if err != nil {
+			return WorkEntryFilter{}, err
+		}
+		minDurationSec := int(minDuration.Seconds())
+		f.MinDurationSec = &minDurationSec
+	}
+
+	autoClosedParam := v.Get("auto_closed")
+	if autoClosedParam != "" {
+		autoClosed, err := strconv.ParseBool(autoClosedParam)
+		if err != nil {
+			return WorkEntryFilter{}, err
+		}
+		f.AutoClosed = &autoClosed
+	}
+
+	return f, nil
+}
+
+// WorkEntryByID godoc
+//
+//	@Summary		Get a work entry
+//	@Description	Get a single work session by ID
+//	@Tags			work
+//	@Produce		json
+//	@Param			entry_id	path		string	true	"Work entry ID"
+//	@Success		200			{object}	WorkHours
+//	@Failure		400			{string}	string	"Invalid work entry ID"
+//	@Failure		404			{string}	string	"Work entry not found"
+//	@Failure		500			{string}	string	"Internal error"
+//	@Router			/work/entries/{entry_id} [get]
+func (h *Handler) WorkEntryByID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	id, err := uuid.FromString(r.PathValue("entry_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	wh, err := h.s.WorkEntryByID(ctx, id)
+	if err != nil {
+		l.Error("get work entry by ID", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(wh)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// UpdateWorkEntry godoc
+//
+//	@Summary		Edit a work entry
+//	@Description	Change the task, start or finish of a finished work entry, spend_time_sec is recomputed
+//	@Tags			work
+//	@Accept			json
+//	@Produce		json
+//	@Param			entry_id	path		string			true	"Work entry ID"
+//	@Param			entry		body		UpdateWorkEntry	true	"Fields to update"
+//	@Success		200			{object}	WorkHours
+//	@Failure		400			{string}	string	"Invalid input"
+//	@Failure		404			{string}	string	"Work entry or task not found"
+//	@Failure		409			{string}	string	"Entry is running, task closed or entry overlaps another entry"
+//	@Failure		500			{string}	string	"Internal error"
+//	@Router			/work/entries/{entry_id} [patch]
+func (h *Handler) UpdateWorkEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	id, err := uuid.FromString(r.PathValue("entry_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var updEntry UpdateWorkEntry
+
+	err = json.NewDecoder(r.Body).Decode(&updEntry)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	wh, err := h.s.UpdateWorkEntry(ctx, id, updEntry)
+	if err != nil {
+		l.Error("update work entry", "error", err)
+		if errors.Is(err, ErrInvalidWorkEntry) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrWorkNotFinished) || errors.Is(err, ErrTaskClosed) || errors.Is(err, ErrWorkOverlap) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(wh)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// DeleteWorkEntry godoc
+//
+//	@Summary		Delete a work entry
+//	@Description	Delete a work entry by ID together with its pauses
+//	@Tags			work
+//	@Produce		json
+//	@Param			entry_id	path		string	true	"Work entry ID"
+//	@Success		200			{string}	string	"Work entry deleted"
+//	@Failure		400			{string}	string	"Invalid work entry ID"
+//	@Failure		404			{string}	string	"Work entry not found"
+//	@Failure		500			{string}	string	"Internal error"
+//	@Router			/work/entries/{entry_id} [delete]
+func (h *Handler) DeleteWorkEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	id, err := uuid.FromString(r.PathValue("entry_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.s.DeleteWorkEntry(ctx, id)
+	if err != nil {
+		l.Error("delete work entry", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// ActiveWork godoc
+//
+//	@Summary		Get running work
+//	@Description	Get all running sessions with user names and the time worked so far
+//	@Tags			work
+//	@Produce		json
+//	@Success		200	{object}	[]ActiveWork
+//	@Failure		500	{string}	string	"Internal error"
+//	@Router			/work/active [get]
+func (h *Handler) ActiveWork(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	active, err := h.s.ActiveWork(ctx)
+	if err != nil {
+		l.Error("get active work", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(active)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// UserActiveWork godoc
+//
+//	@Summary		Get running work of a user
+//	@Description	Get the running sessions of a user and the time worked so far
+//	@Tags			work
+//	@Produce		json
+//	@Param			user_id	path		string	true	"User ID"
+//	@Success		200		{object}	[]ActiveWork
+//	@Failure		400		{string}	string	"Invalid user ID"
+//	@Failure		404		{string}	string	"User not found"
+//	@Failure		500		{string}	string	"Internal error"
+//	@Router			/users/{user_id}/work/active [get]
+func (h *Handler) UserActiveWork(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	id, err := uuid.FromString(r.PathValue("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	active, err := h.s.UserActiveWork(ctx, id)
+	if err != nil {
+		l.Error("get user active work", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(active)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// TaskSpendTimesByUser godoc
+//
+//	@Summary		Get task spend times by user
+//	@Description	Get the time spent on tasks by a user within a specified period, optionally rolled up by project or client, or split into day, week or month buckets each with its own task breakdown.
+//	@Description	Dates are days in the time zone of the user or tz, sessions crossing the period bounds count only their part within it.
+//	@Tags			tasks
+//	@Produce		json
+//	@Param			user_id			path		string	true	"User ID"
+//	@Param			start_date		query		string	false	"Start date in format 'DD-MM-YYYY'"
+//	@Param			end_date		query		string	false	"End date in format 'DD-MM-YYYY', inclusive, default today"
+//	@Param			tz				query		string	false	"IANA time zone of the day boundaries, default the time zone of the user"
+//	@Param			include_running	query		bool	false	"Count running sessions up to now"
+//	@Param			rollup			query		string	false	"Roll up spend times by 'task' (default), 'project' ([]ProjectSpendTime) or 'client' ([]ClientSpendTime)"
+//	@Param			group_by		query		string	false	"Split the period into 'day', 'week' or 'month' buckets with a task breakdown ([]ReportBucket), needs start_date"
+//	@Param			week_start		query		string	false	"First day of a week bucket, default 'monday'"
+//	@Success		200				{object}	[]ProjectSpendTime	"With rollup=project"
+//	@Success		200				{object}	[]ClientSpendTime	"With rollup=client"
+//	@Success		200				{object}	[]ReportBucket		"With group_by"
+//	@Success		200				{object}	[]TaskSpendTime		"Task spend times, []ProjectSpendTime with rollup=project, []ClientSpendTime with rollup=client or []ReportBucket with group_by"
+//	@Failure		400				{string}	string	"Invalid input"
+//	@Failure		404				{string}	string	"User or task not found"
+//	@Failure		500				{string}	string	"Internal error"
+//	@Router			/users/{user_id}/report [get]
+func (h *Handler) TaskSpendTimesByUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+	var err error
+
+	id, err := uuid.FromString(r.PathValue("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	startDateParam := r.URL.Query().Get("start_date")
+	endDateParam := r.URL.Query().Get("end_date")
+	tzParam := r.URL.Query().Get("tz")
+
+	var startDate, endDate time.Time
+	var loc *time.Location
+
+	if startDateParam != "" {
+		startDate, err = time.Parse("02-01-2006", startDateParam)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if endDateParam != "" {
+		endDate, err = time.Parse("02-01-2006", endDateParam)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if tzParam != "" {
+		loc, err = loadTimeZone(tzParam)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var includeRunning bool
+
+	includeRunningParam := r.URL.Query().Get("include_running")
+	if includeRunningParam != "" {
+		includeRunning, err = strconv.ParseBool(includeRunningParam)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy != "" && groupBy != GroupByDay && groupBy != GroupByWeek && groupBy != GroupByMonth {
+		http.Error(w, "group_by must be one of 'day', 'week', 'month'", http.StatusBadRequest)
+		return
+	}
+
+	weekStart := time.Monday
+
+	weekStartParam := r.URL.Query().Get("week_start")
+	if weekStartParam != "" {
+		weekStart, err = parseWeekday(weekStartParam)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	period, err := h.s.ReportPeriod(ctx, id, startDate, endDate, loc, includeRunning)
+	if err != nil {
+		l.Error("get report period", "error", err)
+		if errors.Is(err, ErrInvalidPeriod) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var spendTimesByUser any
+
+	switch rollup := r.URL.Query().Get("rollup"); {
+	case groupBy != "" && rollup != "" && rollup != "task":
+		http.Error(w, "group_by breaks down by task only", http.StatusBadRequest)
+		return
+	case groupBy != "":
+		spendTimesByUser, err = h.s.TaskSpendTimeBuckets(ctx, id, period, groupBy, weekStart)
+	case rollup == "", rollup == "task":
+		spendTimesByUser, err = h.s.TaskSpendTimesByUser(ctx, id, period)
+	case rollup == "project":
+		spendTimesByUser, err = h.s.ProjectSpendTimesByUser(ctx, id, period)
+	case rollup == "client":
+		spendTimesByUser, err = h.s.ClientSpendTimesByUser(ctx, id, period)
+	default:
+		http.Error(w, "rollup must be one of 'task', 'project', 'client'", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		l.Error("get task spend times by user", "error", err)
+		if errors.Is(err, ErrInvalidPeriod) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(spendTimesByUser)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+const (
+	defaultUsersPerPage = 20
+	maxUsersPerPage     = 100
+)
+
+// Users godoc
+//
+//	@Summary		Get users
+//	@Description	Get a list of users with optional filters.
+//	@Description	The total number of matching users is returned in the X-Total-Count header, the next page in the X-Next-Cursor and Link headers.
+//	@Tags			users
+//	@Produce		json
+//	@Param			page			query		int		false	"Page number, ignored with cursor"
+//	@Param			per_page		query		int		false	"Number of users per page, at most 100"
+//	@Param			cursor			query		string	false	"Cursor of the next page from the X-Next-Cursor header"
+//	@Param			sort			query		string	false	"Comma separated sort keys 'field[:asc|desc]' of surname, name, created_at, passport, default 'created_at:desc'"
+//	@Param			id				query		string	false	"User ID"
+//	@Param			passport_series	query		int		false	"Passport series"
+//	@Param			passport_number	query		int		false	"Passport number"
+//	@Param			surname			query		string	false	"Surname"
+//	@Param			name			query		string	false	"Name"
+//	@Param			patronymic		query		string	false	"Patronymic"
+//	@Param			address			query		string	false	"Address"
+//	@Param			match			query		string	false	"How surname, name, patronymic and address are matched: 'exact' (default), 'prefix' or 'contains'"
+//	@Param			ignore_case		query		bool	false	"Match surname, name, patronymic and address case-insensitively"
+//	@Param			q				query		string	false	"Free text search across surname, name, patronymic and address"
+//	@Param			include_deleted	query		bool	false	"Include deleted users"
+//	@Success		200				{object}	[]User
+//	@Header			200				{integer}	X-Total-Count	"Total number of matching users"
+//	@Header			200				{string}	X-Next-Cursor	"Cursor of the next page, absent on the last page"
+//	@Header			200				{string}	Link			"Link to the next page, rel=next"
+//	@Failure		400				{string}	string	"Invalid input"
+//	@Failure		500				{string}	string	"Internal error"
+//	@Router			/users [get]
+func (h *Handler) Users(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+	var err error
+
+	pageParam := r.URL.Query().Get("page")
+	perPageParam := r.URL.Query().Get("per_page")
+	cursorParam := r.URL.Query().Get("cursor")
+
+	page := UserPage{
+		Page:    1,
+		PerPage: defaultUsersPerPage,
+	}
+
+	if pageParam != "" {
+		page.Page, err = strconv.Atoi(pageParam)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if perPageParam != "" {
+		page.PerPage, err = strconv.Atoi(perPageParam)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if page.PerPage < 1 {
+			http.Error(w, "per_page must be positive", http.StatusBadRequest)
+			return
+		}
+
+		page.PerPage = min(page.PerPage, maxUsersPerPage)
+	}
+
+	page.Sort, err = parseUserSort(r.URL.Query().Get("sort"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if cursorParam != "" {
+		page.After, err = decodeUserCursor(cursorParam)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	filter, err := parsUserFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	users, err := h.s.Users(ctx, page, filter)
+	if err != nil {
+		l.Error("get users", "errors", err)
+		if errors.Is(err, ErrCursorMismatch) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(users.Total))
+
+	if users.NextCursor != nil {
+		cursor := encodeUserCursor(*users.NextCursor)
+
+		next := *r.URL
+		query := next.Query()
+		query.Del("page")
+		query.Set("cursor", cursor)
+		next.RawQuery = query.Encode()
+
+		w.Header().Set("X-Next-Cursor", cursor)
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(users.Users)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func parseUserSort(s string) ([]UserSort, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var sort []UserSort
+	seen := make(map[string]bool)
+
+	for _, key := range strings.Split(s, ",") {
+		field, dir, _ := strings.Cut(strings.TrimSpace(key), ":")
+
+		if !isUserSortField(field) {
+			return nil, fmt.Errorf("unknown sort field %q", field)
+		}
+
+		if seen[field] {
+			return nil, fmt.Errorf("duplicate sort field %q", field)
+		}
+		seen[field] = true
+
+		switch dir {
+		case "", "asc":
+			sort = append(sort, UserSort{Field: field})
+		case "desc":
+			sort = append(sort, UserSort{Field: field, Desc: true})
+		default:
+			return nil, fmt.Errorf("sort direction must be 'asc' or 'desc', got %q", dir)
+		}
+	}
+
+	return sort, nil
+}
+
+func encodeUserCursor(c UserCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeUserCursor(s string) (*UserCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	var c UserCursor
+
+	err = json.Unmarshal(b, &c)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	sort, err := parseUserSort(c.Sort)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	// the id tiebreaker is the last column, it's a UUID already
+	cols := userSortColumns(sort)
+	if len(c.Values) != len(cols)-1 {
+		return nil, errors.New("invalid cursor: values don't match sort")
+	}
+
+	for i, v := range c.Values {
+		err = cols[i].checkValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %w", err)
+		}
+	}
+
+	return &c, nil
+}
+
+func parsUserFilter(v url.Values) (f UserFilter, err error) {
+	idParam := v.Get("id")
+	if idParam != "" {
+		id, err := uuid.FromString(idParam)
+		if err != nil {
+			return UserFilter{}, err
+		}
+		f.ID = &id
+	}
+
+	pSeries := v.Get("passport_series")
+	if pSeries != "" {
+		series, err := strconv.Atoi(pSeries)
+		if err != nil {
+			return UserFilter{}, err
+		}
+		f.PassportSeries = &series
+	}
+
+	pNumber := v.Get("passport_number")
+	if pNumber != "" {
+		number, err := strconv.Atoi(pNumber)
+		if err != nil {
+			return UserFilter{}, err
+		}
+		f.PassportNumber = &number
+	}
+
+	surname := v.Get("surname")
+	if surname != "" {
+		f.Surname = &surname
+	}
+
+	name := v.Get("name")
+	if name != "" {
+		f.Name = &name
+	}
+
+	patronymic := v.Get("patronymic")
+	if patronymic != "" {
+		f.Patronymic = &patronymic
+	}
+
+	address := v.Get("address")
+	if address != "" {
+		f.Address = &address
+	}
+
+	f.Match = MatchExact
+
+	match := v.Get("match")
+	switch match {
+	case "":
+	case MatchExact, MatchPrefix, MatchContains:
+		f.Match = match
+	default:
+		return UserFilter{}, errors.New("match must be one of 'exact', 'prefix', 'contains'")
+	}
+
+	ignoreCase := v.Get("ignore_case")
+	if ignoreCase != "" {
+		f.IgnoreCase, err = strconv.ParseBool(ignoreCase)
+		if err != nil {
+			return UserFilter{}, err
+		}
+	}
+
+	f.Query = v.Get("q")
+
+	includeDeleted := v.Get("include_deleted")
+	if includeDeleted != "" {
+		f.IncludeDeleted, err = strconv.ParseBool(includeDeleted)
+		if err != nil {
+			return UserFilter{}, err
+		}
+	}
+
+	return f, nil
+}
+
+// CreateTask godoc
+//
+//	@Summary		Create a new task
+//	@Description	Create a new task to track work on
+//	@Tags			tasks
+//	@Accept			json
+//	@Produce		json
+//	@Param			task	body		CreateTask	true	"Task to create"
+//	@Success		201		{object}	Task
+//	@Failure		400		{string}	string	"Invalid input"
+//	@Failure		404		{string}	string	"Project not found"
+//	@Failure		500		{string}	string	"Internal error"
+//	@Router			/tasks [post]
+func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	var req CreateTask
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(req.Title) == "" {
+		http.Error(w, "task title must not be empty", http.StatusBadRequest)
+		return
+	}
+
+	if req.ProjectID.IsNil() {
+		http.Error(w, "task project_id must be set", http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.s.CreateTask(ctx, req)
+	if err != nil {
+		l.Error("create task", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(task)
+	if err != nil {
+		l.Error("encode task", "error", err)
+		return
+	}
+}
+
+// Tasks godoc
+//
+//	@Summary		Get tasks
+//	@Description	Get a list of tasks with optional filters
+//	@Tags			tasks
+//	@Produce		json
+//	@Param			project_id	query		string	false	"Project ID"
+//	@Success		200			{object}	[]Task
+//	@Failure		400			{string}	string	"Invalid input"
+//	@Failure		500			{string}	string	"Internal error"
+//	@Router			/tasks [get]
+func (h *Handler) Tasks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	var filter TaskFilter
+
+	projectIDParam := r.URL.Query().Get("project_id")
+	if projectIDParam != "" {
+		projectID, err := uuid.FromString(projectIDParam)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.ProjectID = &projectID
+	}
+
+	tasks, err := h.s.Tasks(ctx, filter)
+	if err != nil {
+		l.Error("get tasks", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(tasks)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// TaskByID godoc
+//
+//	@Summary		Get a task
+//	@Description	Get a task by ID
+//	@Tags			tasks
+//	@Produce		json
+//	@Param			task_id	path		string	true	"Task ID"
+//	@Success		200		{object}	Task
+//	@Failure		400		{string}	string	"Invalid task ID"
+//	@Failure		404		{string}	string	"Task not found"
+//	@Failure		500		{string}	string	"Internal error"
+//	@Router			/tasks/{task_id} [get]
+func (h *Handler) TaskByID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	id, err := uuid.FromString(r.PathValue("task_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.s.TaskByID(ctx, id)
+	if err != nil {
+		l.Error("get task by ID", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(task)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// UpdateTask godoc
+//
+//	@Summary		Update an existing task
+//	@Description	Update task details, close or reopen a task
+//	@Tags			tasks
+//	@Accept			json
+//	@Produce		json
+//	@Param			task_id	path		string		true	"Task ID"
+//	@Param			task	body		UpdateTask	true	"Task to update"
+//	@Success		200		{object}	Task
+//	@Failure		400		{string}	string	"Invalid input"
+//	@Failure		404		{string}	string	"Task or project not found"
+//	@Failure		500		{string}	string	"Internal error"
+//	@Router			/tasks/{task_id} [patch]
+func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	id, err := uuid.FromString(r.PathValue("task_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var updTask UpdateTask
+
+	err = json.NewDecoder(r.Body).Decode(&updTask)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if updTask.Title != nil && strings.TrimSpace(*updTask.Title) == "" {
+		http.Error(w, "task title must not be empty", http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.s.UpdateTask(ctx, id, updTask)
+	if err != nil {
+		l.Error("update task", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(task)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// DeleteTask godoc
+//
+//	@Summary		Delete a task
+//	@Description	Delete a task by ID, a task with running sessions can't be deleted
+//	@Tags			tasks
+//	@Produce		json
+//	@Param			task_id	path		string	true	"Task ID"
+//	@Success		200		{string}	string	"Task deleted"
+//	@Failure		400		{string}	string	"Invalid task ID"
+//	@Failure		404		{string}	string	"Task not found"
+//	@Failure		409		{string}	string	"Task has running sessions"
+//	@Failure		500		{string}	string	"Internal error"
+//	@Router			/tasks/{task_id} [delete]
+func (h *Handler) DeleteTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	id, err := uuid.FromString(r.PathValue("task_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.s.DeleteTask(ctx, id)
+	if err != nil {
+		l.Error("delete task", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrInUse) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// CreateClient godoc
+//
+//	@Summary		Create a new client
+//	@Description	Create a new client to bill projects to
+//	@Tags			clients
+//	@Accept			json
+//	@Produce		json
+//	@Param			client	body		CreateClient	true	"Client to create"
+//	@Success		201		{object}	Client
+//	@Failure		400		{string}	string	"Invalid input"
+//	@Failure		500		{string}	string	"Internal error"
+//	@Router			/clients [post]
+func (h *Handler) CreateClient(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	var req CreateClient
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		http.Error(w, "client name must not be empty", http.StatusBadRequest)
+		return
+	}
+
+	client, err := h.s.CreateClient(ctx, req)
+	if err != nil {
+		l.Error("create client", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(client)
+	if err != nil {
+		l.Error("encode client", "error", err)
 		return
 	}
+}
+
+// Clients godoc
+//
+//	@Summary		Get clients
+//	@Description	Get a list of clients
+//	@Tags			clients
+//	@Produce		json
+//	@Success		200	{object}	[]Client
+//	@Failure		500	{string}	string	"Internal error"
+//	@Router			/clients [get]
+func (h *Handler) Clients(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
 
-	passportNumber, err := strconv.Atoi(parts[1])
+	clients, err := h.s.Clients(ctx)
 	if err != nil {
-		http.Error(w, "passport number must be a integer", http.StatusBadRequest)
+		l.Error("get clients", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	err = h.s.CreateUser(ctx, passportSeries, passportNumber)
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(clients)
 	if err != nil {
-		l.Error("create user", "error", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
 
-// UpdateUser godoc
+// ClientByID godoc
 //
-//	@Summary		Update an existing user
-//	@Description	Update user details
-//	@Tags			users
-//	@Accept			json
+//	@Summary		Get a client
+//	@Description	Get a client by ID
+//	@Tags			clients
 //	@Produce		json
-//	@Param			user	body		UpdateUser	true	"User to update"
-//	@Success		200		{object}	User
-//	@Failure		400		{string}	string	"Invalid input"
-//	@Failure		404		{string}	string	"User not found"
-//	@Failure		500		{string}	string	"Internal error"
-//	@Router			/users [patch]
-func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+//	@Param			client_id	path		string	true	"Client ID"
+//	@Success		200			{object}	Client
+//	@Failure		400			{string}	string	"Invalid client ID"
+//	@Failure		404			{string}	string	"Client not found"
+//	@Failure		500			{string}	string	"Internal error"
+//	@Router			/clients/{client_id} [get]
+func (h *Handler) ClientByID(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
 
-	var updUser UpdateUser
-
-	err := json.NewDecoder(r.Body).Decode(&updUser)
+	id, err := uuid.FromString(r.PathValue("client_id"))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	user, err := h.s.UpdateUser(ctx, updUser)
+	client, err := h.s.ClientByID(ctx, id)
 	if err != nil {
-		l.Error("update user", "error", err)
+		l.Error("get client by ID", "error", err)
 		if errors.Is(err, ErrNotFound) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
@@ -125,39 +2304,53 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(user)
+	err = json.NewEncoder(w).Encode(client)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
 
-// DeleteUser godoc
+// UpdateClient godoc
 //
-//	@Summary		Delete a user
-//	@Description	Delete a user by ID
-//	@Tags			users
+//	@Summary		Update an existing client
+//	@Description	Update client details
+//	@Tags			clients
+//	@Accept			json
 //	@Produce		json
-//	@Param			user_id	path		string	true	"User ID"
-//	@Success		200		{string}	string	"User deleted"
-//	@Failure		400		{string}	string	"Invalid user ID"
-//	@Failure		404		{string}	string	"User not found"
-//	@Failure		500		{string}	string	"Internal error"
-//	@Router			/users/{user_id} [delete]
-func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+//	@Param			client_id	path		string			true	"Client ID"
+//	@Param			client		body		UpdateClient	true	"Client to update"
+//	@Success		200			{object}	Client
+//	@Failure		400			{string}	string	"Invalid input"
+//	@Failure		404			{string}	string	"Client not found"
+//	@Failure		500			{string}	string	"Internal error"
+//	@Router			/clients/{client_id} [patch]
+func (h *Handler) UpdateClient(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-
 	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
 
-	id, err := uuid.FromString(r.PathValue("user_id"))
+	id, err := uuid.FromString(r.PathValue("client_id"))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	err = h.s.DeleteUser(ctx, id)
+	var updClient UpdateClient
+
+	err = json.NewDecoder(r.Body).Decode(&updClient)
 	if err != nil {
-		l.Error("delete user", "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if updClient.Name != nil && strings.TrimSpace(*updClient.Name) == "" {
+		http.Error(w, "client name must not be empty", http.StatusBadRequest)
+		return
+	}
+
+	client, err := h.s.UpdateClient(ctx, id, updClient)
+	if err != nil {
+		l.Error("update client", "error", err)
 		if errors.Is(err, ErrNotFound) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
@@ -165,70 +2358,72 @@ func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-}
 
-type StartWorkRequest struct {
-	UserID uuid.UUID `json:"user_id"`
-	TaskID uuid.UUID `json:"task_id"`
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(client)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 }
 
-// StartWork godoc
+// DeleteClient godoc
 //
-//	@Summary		Start work on a task
-//	@Description	Start work on a task for a user
-//	@Tags			work
-//	@Accept			json
+//	@Summary		Delete a client
+//	@Description	Delete a client by ID, the client must have no projects
+//	@Tags			clients
 //	@Produce		json
-//	@Param			startWorkRequest	body		StartWorkRequest	true	"Start work request"
-//	@Success		200					{string}	string				"Work started"
-//	@Failure		400					{string}	string				"Invalid input"
-//	@Failure		500					{string}	string				"Internal error"
-//	@Router			/work/start [post]
-func (h *Handler) StartWork(w http.ResponseWriter, r *http.Request) {
+//	@Param			client_id	path		string	true	"Client ID"
+//	@Success		200			{string}	string	"Client deleted"
+//	@Failure		400			{string}	string	"Invalid client ID"
+//	@Failure		404			{string}	string	"Client not found"
+//	@Failure		409			{string}	string	"Client has projects"
+//	@Failure		500			{string}	string	"Internal error"
+//	@Router			/clients/{client_id} [delete]
+func (h *Handler) DeleteClient(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-
 	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
 
-	var req StartWorkRequest
-
-	err := json.NewDecoder(r.Body).Decode(&req)
+	id, err := uuid.FromString(r.PathValue("client_id"))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	err = h.s.StartWork(ctx, req.UserID, req.TaskID)
+	err = h.s.DeleteClient(ctx, id)
 	if err != nil {
-		l.Error("start work", "error", err)
+		l.Error("delete client", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrInUse) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
 
-type FinishWorkRequest struct {
-	UserID uuid.UUID `json:"user_id"`
-	TaskID uuid.UUID `json:"task_id"`
-}
-
-// FinishWork godoc
+// CreateProject godoc
 //
-//	@Summary		Finish work on a task
-//	@Description	Finish work on a task for a user
-//	@Tags			work
+//	@Summary		Create a new project
+//	@Description	Create a new project for a client
+//	@Tags			projects
 //	@Accept			json
 //	@Produce		json
-//	@Param			finishWorkRequest	body		FinishWorkRequest	true	"Finish work request"
-//	@Success		200					{string}	string				"Work finished"
-//	@Failure		400					{string}	string				"Invalid input"
-//	@Failure		404					{string}	string				"Task not found"
-//	@Failure		500					{string}	string				"Internal error"
-//	@Router			/work/finish [post]
-func (h *Handler) FinishWork(w http.ResponseWriter, r *http.Request) {
+//	@Param			project	body		CreateProject	true	"Project to create"
+//	@Success		201		{object}	Project
+//	@Failure		400		{string}	string	"Invalid input"
+//	@Failure		404		{string}	string	"Client not found"
+//	@Failure		500		{string}	string	"Internal error"
+//	@Router			/projects [post]
+func (h *Handler) CreateProject(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-
 	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
 
-	var req FinishWorkRequest
+	var req CreateProject
 
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
@@ -236,73 +2431,103 @@ func (h *Handler) FinishWork(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.s.FinishWork(ctx, req.UserID, req.TaskID)
+	if strings.TrimSpace(req.Name) == "" {
+		http.Error(w, "project name must not be empty", http.StatusBadRequest)
+		return
+	}
+
+	if req.ClientID.IsNil() {
+		http.Error(w, "project client_id must be set", http.StatusBadRequest)
+		return
+	}
+
+	project, err := h.s.CreateProject(ctx, req)
 	if err != nil {
-		l.Error("finish work", "error", err)
+		l.Error("create project", "error", err)
 		if errors.Is(err, ErrNotFound) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
 		}
-
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(project)
+	if err != nil {
+		l.Error("encode project", "error", err)
+		return
+	}
 }
 
-// TaskSpendTimesByUser godoc
+// Projects godoc
 //
-//	@Summary		Get task spend times by user
-//	@Description	Get the time spent on tasks by a user within a specified period
-//	@Tags			tasks
+//	@Summary		Get projects
+//	@Description	Get a list of projects with optional filters
+//	@Tags			projects
 //	@Produce		json
-//	@Param			user_id		path		string	true	"User ID"
-//	@Param			start_date	query		string	false	"Start date in format 'DD-MM-YYYY'"
-//	@Param			end_date	query		string	false	"End date in format 'DD-MM-YYYY'"
-//	@Success		200			{object}	[]TaskSpendTime
+//	@Param			client_id	query		string	false	"Client ID"
+//	@Success		200			{object}	[]Project
 //	@Failure		400			{string}	string	"Invalid input"
-//	@Failure		404			{string}	string	"User or task not found"
 //	@Failure		500			{string}	string	"Internal error"
-//	@Router			/users/{user_id}/report [get]
-func (h *Handler) TaskSpendTimesByUser(w http.ResponseWriter, r *http.Request) {
+//	@Router			/projects [get]
+func (h *Handler) Projects(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
-	var err error
-
-	id, err := uuid.FromString(r.PathValue("user_id"))
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
 
-	startDate := r.URL.Query().Get("start_date")
-	endDate := r.URL.Query().Get("end_date")
+	var filter ProjectFilter
 
-	var period Period
-
-	if startDate != "" {
-		period.StartDate, err = time.Parse("02-01-2006", startDate)
+	clientIDParam := r.URL.Query().Get("client_id")
+	if clientIDParam != "" {
+		clientID, err := uuid.FromString(clientIDParam)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
+		filter.ClientID = &clientID
 	}
 
-	if endDate != "" {
-		period.EndDate, err = time.Parse("02-01-2006", endDate)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
+	projects, err := h.s.Projects(ctx, filter)
+	if err != nil {
+		l.Error("get projects", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	if period.StartDate.Equal(period.EndDate) {
-		// to get values by one day (with the same dates)
-		period.EndDate = period.EndDate.Add(time.Hour * 24)
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(projects)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
+}
+
+// ProjectByID godoc
+//
+//	@Summary		Get a project
+//	@Description	Get a project by ID
+//	@Tags			projects
+//	@Produce		json
+//	@Param			project_id	path		string	true	"Project ID"
+//	@Success		200			{object}	Project
+//	@Failure		400			{string}	string	"Invalid project ID"
+//	@Failure		404			{string}	string	"Project not found"
+//	@Failure		500			{string}	string	"Internal error"
+//	@Router			/projects/{project_id} [get]
+func (h *Handler) ProjectByID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
 
-	spendTimesByUser, err := h.s.TaskSpendTimesByUser(ctx, id, period)
+	id, err := uuid.FromString(r.PathValue("project_id"))
 	if err != nil {
-		l.Error("get task spend times by user", "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	project, err := h.s.ProjectByID(ctx, id)
+	if err != nil {
+		l.Error("get project by ID", "error", err)
 		if errors.Is(err, ErrNotFound) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
@@ -312,127 +2537,104 @@ func (h *Handler) TaskSpendTimesByUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(spendTimesByUser)
+	err = json.NewEncoder(w).Encode(project)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
 
-// Users godoc
+// UpdateProject godoc
 //
-//	@Summary		Get users
-//	@Description	Get a list of users with optional filters
-//	@Tags			users
+//	@Summary		Update an existing project
+//	@Description	Update project details or move it to another client
+//	@Tags			projects
+//	@Accept			json
 //	@Produce		json
-//	@Param			page			query		int		false	"Page number"
-//	@Param			per_page		query		int		false	"Number of users per page"
-//	@Param			id				query		string	false	"User ID"
-//	@Param			passport_series	query		int		false	"Passport series"
-//	@Param			passport_number	query		int		false	"Passport number"
-//	@Param			surname			query		string	false	"Surname"
-//	@Param			name			query		string	false	"Name"
-//	@Param			patronymic		query		string	false	"Patronymic"
-//	@Param			address			query		string	false	"Address"
-//	@Success		200				{object}	[]User
-//	@Failure		400				{string}	string	"Invalid input"
-//	@Failure		500				{string}	string	"Internal error"
-//	@Router			/users [get]
-func (h *Handler) Users(w http.ResponseWriter, r *http.Request) {
+//	@Param			project_id	path		string			true	"Project ID"
+//	@Param			project		body		UpdateProject	true	"Project to update"
+//	@Success		200			{object}	Project
+//	@Failure		400			{string}	string	"Invalid input"
+//	@Failure		404			{string}	string	"Project or client not found"
+//	@Failure		500			{string}	string	"Internal error"
+//	@Router			/projects/{project_id} [patch]
+func (h *Handler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
-	var err error
-
-	pageParam := r.URL.Query().Get("page")
-	perPageParam := r.URL.Query().Get("per_page")
 
-	page := 1
-	perPage := 20
-
-	if pageParam != "" {
-		page, err = strconv.Atoi(pageParam)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
+	id, err := uuid.FromString(r.PathValue("project_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	if perPageParam != "" {
-		perPage, err = strconv.Atoi(perPageParam)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-	}
+	var updProject UpdateProject
 
-	filter, err := parsUserFilter(r.URL.Query())
+	err = json.NewDecoder(r.Body).Decode(&updProject)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	users, err := h.s.Users(ctx, page, perPage, filter)
+	if updProject.Name != nil && strings.TrimSpace(*updProject.Name) == "" {
+		http.Error(w, "project name must not be empty", http.StatusBadRequest)
+		return
+	}
+
+	project, err := h.s.UpdateProject(ctx, id, updProject)
 	if err != nil {
-		l.Error("get users", "errors", err)
+		l.Error("update project", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(users)
+	err = json.NewEncoder(w).Encode(project)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
 
-func parsUserFilter(v url.Values) (f UserFilter, err error) {
-	idParam := v.Get("id")
-	if idParam != "" {
-		id, err := uuid.FromString(idParam)
-		if err != nil {
-			return UserFilter{}, err
-		}
-		f.ID = &id
-	}
+// DeleteProject godoc
+//
+//	@Summary		Delete a project
+//	@Description	Delete a project by ID, the project must have no tasks
+//	@Tags			projects
+//	@Produce		json
+//	@Param			project_id	path		string	true	"Project ID"
+//	@Success		200			{string}	string	"Project deleted"
+//	@Failure		400			{string}	string	"Invalid project ID"
+//	@Failure		404			{string}	string	"Project not found"
+//	@Failure		409			{string}	string	"Project has tasks"
+//	@Failure		500			{string}	string	"Internal error"
+//	@Router			/projects/{project_id} [delete]
+func (h *Handler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
 
-	pSeries := v.Get("passport_series")
-	if pSeries != "" {
-		series, err := strconv.Atoi(pSeries)
-		if err != nil {
-			return UserFilter{}, err
-		}
-		f.PassportSeries = &series
+	id, err := uuid.FromString(r.PathValue("project_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	pNumber := v.Get("passport_number")
-	if pNumber != "" {
-		number, err := strconv.Atoi(pNumber)
-		if err != nil {
-			return UserFilter{}, err
+	err = h.s.DeleteProject(ctx, id)
+	if err != nil {
+		l.Error("delete project", "error", err)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
 		}
-		f.PassportNumber = &number
-	}
-
-	surname := v.Get("surname")
-	if surname != "" {
-		f.Surname = &surname
-	}
-
-	name := v.Get("name")
-	if name != "" {
-		f.Name = &name
-	}
-
-	patronymic := v.Get("patronymic")
-	if patronymic != "" {
-		f.Patronymic = &patronymic
-	}
-
-	address := v.Get("address")
-	if address != "" {
-		f.Address = &address
+		if errors.Is(err, ErrInUse) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
-
-	return f, nil
 }
@@ -0,0 +1,173 @@
+package tracker
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    []int
+		wantErr bool
+	}{
+		{header: `"3"`, want: []int{3}},
+		{header: ` "3" `, want: []int{3}},
+		{header: `*`, want: nil},
+		{header: `"1", "2"`, want: []int{1, 2}},
+		{header: `"1",,"2",`, want: []int{1, 2}},
+		{header: `W/"3"`, want: []int{}},
+		{header: `W/"3", "4"`, want: []int{4}},
+		{header: `"abc"`, want: []int{}},
+		{header: `"0"`, want: []int{}},
+		{header: `"+3"`, want: []int{}},
+		{header: `"03"`, want: []int{}},
+		{header: `""`, want: []int{}},
+		{header: `3`, wantErr: true},
+		{header: `"3`, wantErr: true},
+		{header: `"3" "4"`, wantErr: true},
+		{header: `"3"x`, wantErr: true},
+		{header: `w/"3"`, wantErr: true},
+		{header: `*, "3"`, wantErr: true},
+		{header: `,`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, err := parseIfMatch(tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseIfMatch(%q) error = %v, want error %v", tt.header, err, tt.wantErr)
+			}
+			if errors.Is(err, errIfMatchMissing) {
+				t.Fatalf("parseIfMatch(%q) reports a missing header", tt.header)
+			}
+			if !slices.Equal(got, tt.want) || (got == nil) != (tt.want == nil) {
+				t.Errorf("parseIfMatch(%q) = %#v, want %#v", tt.header, got, tt.want)
+			}
+		})
+	}
+
+	for _, h := range []string{"", "  "} {
+		_, err := parseIfMatch(h)
+		if !errors.Is(err, errIfMatchMissing) {
+			t.Errorf("parseIfMatch(%q) error = %v, want %v", h, err, errIfMatchMissing)
+		}
+	}
+}
+
+func TestDecodeUserCursor(t *testing.T) {
+	id := uuid.Must(uuid.NewV4())
+
+	tests := []struct {
+		name    string
+		cursor  UserCursor
+		wantErr bool
+	}{
+		{name: "default sort", cursor: UserCursor{ID: id}},
+		{name: "text", cursor: UserCursor{Sort: "surname:asc", Values: []string{"Ivanov"}, ID: id}},
+		{name: "timestamp", cursor: UserCursor{Sort: "created_at:desc", Values: []string{"2024-08-26T10:00:00.123456Z"}, ID: id}},
+		{name: "integers", cursor: UserCursor{Sort: "passport:asc", Values: []string{"1234", "567890"}, ID: id}},
+		{name: "bad timestamp", cursor: UserCursor{Sort: "created_at:desc", Values: []string{"yesterday"}, ID: id}, wantErr: true},
+		{name: "bad integer", cursor: UserCursor{Sort: "passport:asc", Values: []string{"1234", "x"}, ID: id}, wantErr: true},
+		{name: "integer out of range", cursor: UserCursor{Sort: "passport:asc", Values: []string{"1234", "99999999999"}, ID: id}, wantErr: true},
+		{name: "nul in text", cursor: UserCursor{Sort: "surname:asc", Values: []string{"a\x00b"}, ID: id}, wantErr: true},
+		{name: "too few values", cursor: UserCursor{Sort: "passport:asc", Values: []string{"1234"}, ID: id}, wantErr: true},
+		{name: "too many values", cursor: UserCursor{Values: []string{"x"}, ID: id}, wantErr: true},
+		{name: "unknown sort field", cursor: UserCursor{Sort: "salary:asc", Values: []string{"1"}, ID: id}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := decodeUserCursor(encodeUserCursor(tt.cursor))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeUserCursor error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && c.ID != id {
+				t.Errorf("decoded cursor ID = %s, want %s", c.ID, id)
+			}
+		})
+	}
+
+	for _, s := range []string{"%%%", "bm90IGpzb24", "eyJpZCI6ICJ4In0"} {
+		_, err := decodeUserCursor(s)
+		if err == nil {
+			t.Errorf("decodeUserCursor(%q) succeeded, want error", s)
+		}
+	}
+}
+
+func TestParseImportJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []ImportUserRow
+		wantErr bool
+	}{
+		{
+			name: "array",
+			body: `[{"passportNumber": "1234 567890"}, {"passportNumber": "4321 098765", "surname": "Ivanov", "name": "Ivan"}]`,
+			want: []ImportUserRow{
+				{PassportNumber: "1234 567890"},
+				{PassportNumber: "4321 098765", Surname: "Ivanov", Name: "Ivan"},
+			},
+		},
+		{name: "empty array", body: "[]\n", want: []ImportUserRow{}},
+		{name: "ndjson", body: "{\"passportNumber\": \"1234 567890\"}\n{\"passportNumber\": \"4321 098765\"}\n", wantErr: true},
+		{name: "single object", body: `{"passportNumber": "1234 567890"}`, wantErr: true},
+		{name: "trailing data", body: `[] []`, wantErr: true},
+		{name: "truncated", body: `[{"passportNumber": "1234 567890"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseImportJSON(strings.NewReader(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseImportJSON error = %v, want error %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseImportJSON = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWorkEntryPage(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    WorkEntryPage
+		wantErr bool
+	}{
+		{query: "", want: WorkEntryPage{Page: 1, PerPage: defaultWorkEntriesPerPage}},
+		{query: "page=3&per_page=20", want: WorkEntryPage{Page: 3, PerPage: 20}},
+		{query: "per_page=100000", want: WorkEntryPage{Page: 1, PerPage: maxWorkEntriesPerPage}},
+		{query: "page=2147483647&per_page=500", want: WorkEntryPage{Page: maxWorkEntriesPage, PerPage: maxWorkEntriesPerPage}},
+		{query: "page=2147483648", wantErr: true},
+		{query: "page=9223372036854775807&per_page=500", wantErr: true},
+		{query: "page=0", wantErr: true},
+		{query: "page=x", wantErr: true},
+		{query: "per_page=0", wantErr: true},
+		{query: "per_page=-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			v, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := parseWorkEntryPage(v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseWorkEntryPage(%q) error = %v, want error %t", tt.query, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseWorkEntryPage(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,24 +2,32 @@ package tracker
 
 import (
 	"context"
+	"crypto/subtle"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/gofrs/uuid"
 )
 
 type Middleware struct {
-	l *slog.Logger
+	l          *slog.Logger
+	adminToken string
 }
 
-func NewMiddleware(l *slog.Logger) *Middleware {
+func NewMiddleware(l *slog.Logger, adminToken string) *Middleware {
 	return &Middleware{
-		l: l,
+		l:          l,
+		adminToken: adminToken,
 	}
 }
 
 type LoggerCtxKey struct{}
 
+type AdminCtxKey struct{}
+
+type ActorCtxKey struct{}
+
 func (m *Middleware) Log(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		l := m.l.With("request_id", uuid.Must(uuid.NewV4()))
@@ -31,3 +39,52 @@ func (m *Middleware) Log(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// Auth marks requests bearing the admin token and keeps the name from the
+// X-Actor header, see isAdmin and actor.
+func (m *Middleware) Auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		admin := ok && m.adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(m.adminToken)) == 1
+
+		ctx := context.WithValue(r.Context(), AdminCtxKey{}, admin)
+		ctx = context.WithValue(ctx, ActorCtxKey{}, strings.TrimSpace(r.Header.Get("X-Actor")))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func isAdmin(ctx context.Context) bool {
+	admin, _ := ctx.Value(AdminCtxKey{}).(bool)
+	return admin
+}
+
+// actorName is the X-Actor header of the request, empty when it's not set.
+func actorName(ctx context.Context) string {
+	name, _ := ctx.Value(ActorCtxKey{}).(string)
+	return name
+}
+
+// actor is who is recorded in user history. It comes from the authenticated
+// identity, the X-Actor header can't be verified and only qualifies it, e.g.
+// "admin:alice" for the admin token or "unverified:alice" without it.
+// Background jobs outside of a request are "system".
+func actor(ctx context.Context) string {
+	if ctx.Value(ActorCtxKey{}) == nil {
+		return "system"
+	}
+
+	name := actorName(ctx)
+
+	switch {
+	case isAdmin(ctx) && name != "":
+		return "admin:" + name
+	case isAdmin(ctx):
+		return "admin"
+	case name != "":
+		return "unverified:" + name
+	default:
+		return "anonymous"
+	}
+}
@@ -0,0 +1,57 @@
+package tracker
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthActor(t *testing.T) {
+	tests := []struct {
+		name      string
+		token     string
+		xActor    string
+		want      string
+		wantAdmin bool
+	}{
+		{name: "anonymous", want: "anonymous"},
+		{name: "unverified name", xActor: "alice", want: "unverified:alice"},
+		{name: "wrong token", token: "guess", xActor: "alice", want: "unverified:alice"},
+		{name: "admin", token: "secret", want: "admin", wantAdmin: true},
+		{name: "admin with name", token: "secret", xActor: " alice ", want: "admin:alice", wantAdmin: true},
+	}
+
+	m := NewMiddleware(slog.Default(), "secret")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			var admin bool
+
+			h := m.Auth(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = actor(r.Context())
+				admin = isAdmin(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodPatch, "/users/1", nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			if tt.xActor != "" {
+				r.Header.Set("X-Actor", tt.xActor)
+			}
+
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.want || admin != tt.wantAdmin {
+				t.Errorf("actor = %q, admin = %v, want %q, %v", got, admin, tt.want, tt.wantAdmin)
+			}
+		})
+	}
+
+	if got := actor(context.Background()); got != "system" {
+		t.Errorf("actor outside of a request = %q, want system", got)
+	}
+}
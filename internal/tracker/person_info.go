@@ -0,0 +1,207 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/bits"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"time"
+)
+
+var ErrPersonNotFound = errors.New("person not found")
+
+// ErrPersonInfoRejected is returned when the person info source refuses
+// a passport, retrying such a request is pointless.
+var ErrPersonInfoRejected = errors.New("person info rejected")
+
+type PersonInfo struct {
+	Surname    string `json:"surname"`
+	Name       string `json:"name"`
+	Patronymic string `json:"patronymic"`
+	Address    string `json:"address"`
+}
+
+// PersonInfoProvider enriches a user by passport with data from an external source.
+type PersonInfoProvider interface {
+	PersonInfo(ctx context.Context, passportSeries, passportNumber int) (PersonInfo, error)
+}
+
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// backoff returns an exponential delay with full jitter before the given retry.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	if p.BaseDelay <= 0 || p.MaxDelay <= 0 {
+		return 0
+	}
+
+	return rand.N(expDelay(p.BaseDelay, p.MaxDelay, retry))
+}
+
+// expDelay returns base doubled n times, capped at max. Both must be positive.
+func expDelay(base, max time.Duration, n int) time.Duration {
+	// base is shifted only while it stays within max, so it can't overflow
+	if n < bits.Len64(uint64(max/base)) {
+		return base << n
+	}
+
+	return max
+}
+
+type responseCodeError struct {
+	code int
+}
+
+func (e *responseCodeError) Error() string {
+	return fmt.Sprintf("unexpected response code: %d", e.code)
+}
+
+// HTTPPersonInfoProvider requests the People info API described in README.
+// Timeouts, transport errors and 5xx responses are retried, 4xx are not.
+type HTTPPersonInfoProvider struct {
+	client  *http.Client
+	apiURL  string
+	retry   RetryPolicy
+	breaker *CircuitBreaker
+}
+
+func NewHTTPPersonInfoProvider(apiURL string, timeout time.Duration, retry RetryPolicy, breaker *CircuitBreaker) *HTTPPersonInfoProvider {
+	client := &http.Client{
+		Timeout: timeout,
+	}
+
+	return &HTTPPersonInfoProvider{
+		client:  client,
+		apiURL:  apiURL,
+		retry:   retry,
+		breaker: breaker,
+	}
+}
+
+func (p *HTTPPersonInfoProvider) PersonInfo(ctx context.Context, passportSeries, passportNumber int) (PersonInfo, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	var err error
+
+	for attempt := 0; attempt < max(p.retry.MaxAttempts, 1); attempt++ {
+		if attempt > 0 {
+			delay := p.retry.backoff(attempt - 1)
+			l.Debug("retry person info request", "attempt", attempt+1, "delay", delay, "error", err)
+
+			select {
+			case <-ctx.Done():
+				return PersonInfo{}, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		err = p.breaker.Allow()
+		if err != nil {
+			return PersonInfo{}, err
+		}
+
+		var info PersonInfo
+		info, err = p.request(ctx, passportSeries, passportNumber)
+
+		var codeErr *responseCodeError
+
+		switch {
+		case err == nil:
+			p.breaker.Success()
+			return info, nil
+		case ctx.Err() != nil:
+			p.breaker.Cancel()
+			return PersonInfo{}, err
+		case errors.As(err, &codeErr) && codeErr.code < http.StatusInternalServerError:
+			// the API is up and answered, but refused this passport
+			p.breaker.Success()
+			if codeErr.code == http.StatusNotFound {
+				return PersonInfo{}, fmt.Errorf("%w: %w", ErrPersonInfoRejected, ErrPersonNotFound)
+			}
+			return PersonInfo{}, fmt.Errorf("%w: %w", ErrPersonInfoRejected, err)
+		default:
+			p.breaker.Failure()
+		}
+	}
+
+	return PersonInfo{}, err
+}
+
+func (p *HTTPPersonInfoProvider) request(ctx context.Context, passportSeries, passportNumber int) (PersonInfo, error) {
+	url := fmt.Sprintf("%s/info?passportSerie=%d&passportNumber=%d", p.apiURL, passportSeries, passportNumber)
+
+	var req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return PersonInfo{}, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return PersonInfo{}, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return PersonInfo{}, &responseCodeError{code: resp.StatusCode}
+	}
+
+	var info PersonInfo
+
+	err = json.NewDecoder(resp.Body).Decode(&info)
+	if err != nil {
+		return PersonInfo{}, fmt.Errorf("parse body: %w", err)
+	}
+
+	return info, nil
+}
+
+func (p *HTTPPersonInfoProvider) CircuitBreakerStatus() CircuitBreakerStatus {
+	return p.breaker.Status()
+}
+
+// StaticPersonInfoProvider serves person info from a fixture file with
+// passport numbers in format '1234 567890' as keys.
+type StaticPersonInfoProvider struct {
+	people map[string]PersonInfo
+}
+
+func NewStaticPersonInfoProvider(fixturePath string) (*StaticPersonInfoProvider, error) {
+	f, err := os.Open(fixturePath)
+	if err != nil {
+		return nil, fmt.Errorf("open fixture: %w", err)
+	}
+	defer f.Close()
+
+	var people map[string]PersonInfo
+
+	err = json.NewDecoder(f).Decode(&people)
+	if err != nil {
+		return nil, fmt.Errorf("parse fixture: %w", err)
+	}
+
+	return &StaticPersonInfoProvider{people: people}, nil
+}
+
+func (p *StaticPersonInfoProvider) PersonInfo(_ context.Context, passportSeries, passportNumber int) (PersonInfo, error) {
+	info, ok := p.people[fmt.Sprintf("%04d %06d", passportSeries, passportNumber)]
+	if !ok {
+		return PersonInfo{}, fmt.Errorf("%w: %w", ErrPersonInfoRejected, ErrPersonNotFound)
+	}
+
+	return info, nil
+}
+
+// NonePersonInfoProvider leaves users without enrichment.
+type NonePersonInfoProvider struct{}
+
+func (NonePersonInfoProvider) PersonInfo(context.Context, int, int) (PersonInfo, error) {
+	return PersonInfo{}, nil
+}
@@ -0,0 +1,42 @@
+package tracker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		retry  int
+		want   time.Duration // upper bound of the jittered delay
+	}{
+		{"first retry", RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}, 0, time.Second},
+		{"doubles", RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}, 3, 8 * time.Second},
+		{"capped", RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}, 6, time.Minute},
+		{"shift overflows", RetryPolicy{BaseDelay: time.Hour, MaxDelay: 24 * time.Hour}, 25, 24 * time.Hour},
+		{"retry beyond 64 bits", RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: time.Hour}, 1000, time.Hour},
+		{"no base delay", RetryPolicy{MaxDelay: time.Minute}, 5, 0},
+		{"no max delay", RetryPolicy{BaseDelay: time.Second}, 5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var longest time.Duration
+
+			for range 100 {
+				delay := tt.policy.backoff(tt.retry)
+				if delay < 0 || (delay >= tt.want && delay != 0) {
+					t.Fatalf("backoff(%d) = %v, want in [0, %v)", tt.retry, delay, tt.want)
+				}
+				longest = max(longest, delay)
+			}
+
+			// with full jitter 100 draws all below half the bound are practically impossible
+			if longest < tt.want/2 {
+				t.Fatalf("backoff(%d) never exceeded %v, want up to %v", tt.retry, longest, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package tracker
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type Project struct {
+	ID        uuid.UUID `json:"id"`
+	ClientID  uuid.UUID `json:"client_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateProject struct {
+	ClientID uuid.UUID `json:"client_id"`
+	Name     string    `json:"name"`
+}
+
+type UpdateProject struct {
+	ClientID *uuid.UUID `json:"client_id"`
+	Name     *string    `json:"name"`
+}
+
+type ProjectFilter struct {
+	ClientID *uuid.UUID
+}
@@ -4,46 +4,111 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// querier is implemented by both *pgxpool.Pool and pgx.Tx.
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 type Repository struct {
-	db *pgxpool.Pool
+	db querier
 }
 
 func NewRepository(db *pgxpool.Pool) *Repository {
 	return &Repository{db: db}
 }
 
+// WithTx runs fn in a transaction, a nested call creates a savepoint.
+func (r *Repository) WithTx(ctx context.Context, fn func(repo *Repository) error) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return fn(&Repository{db: tx})
+	})
+}
+
+// CreateUser returns *UserExistsError if a user with the same passport exists.
 func (r *Repository) CreateUser(ctx context.Context, u User) error {
 	q := `
-INSERT INTO users (id, passport_series, passport_number, surname, name, patronymic, address, created_at) 
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO users (id, passport_series, passport_number, surname, name, patronymic, address, enrichment_status, enrichment_next_at, created_at) 
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (passport_series, passport_number) WHERE deleted_at ISNULL DO NOTHING
 `
 
-	_, err := r.db.Exec(ctx, q, u.ID, u.PassportSeries, u.PassportNumber, u.Surname, u.Name, u.Patronymic, u.Address, u.CreatedAt)
+	var enrichmentNextAt *time.Time
+	if u.EnrichmentStatus == EnrichmentPending {
+		enrichmentNextAt = &u.CreatedAt
+	}
+
+	res, err := r.db.Exec(ctx, q, u.ID, u.PassportSeries, u.PassportNumber, u.Surname, u.Name, u.Patronymic, u.Address, u.EnrichmentStatus, enrichmentNextAt, u.CreatedAt)
 	if err != nil {
 		return err
 	}
 
+	if res.RowsAffected() == 0 {
+		id, err := r.UserIDByPassport(ctx, u.PassportSeries, u.PassportNumber)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		return &UserExistsError{ID: id}
+	}
+
 	return nil
 }
 
+func (r *Repository) UserIDByPassport(ctx context.Context, passportSeries, passportNumber int) (id uuid.UUID, err error) {
+	q := `SELECT id FROM users WHERE passport_series = $1 AND passport_number = $2 AND deleted_at ISNULL`
+
+	err = r.db.QueryRow(ctx, q, passportSeries, passportNumber).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrNotFound
+		}
+		return uuid.Nil, err
+	}
+
+	return id, nil
+}
+
+const uniqueViolationCode = "23505"
+
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode && pgErr.ConstraintName == constraint
+}
+
 func (r *Repository) UpdateUser(ctx context.Context, updUser UpdateUser) error {
 	setSQL, args := setUpdateProductSQL(updUser)
+	if len(args) == 0 {
+		return nil
+	}
 
-	q := fmt.Sprintf("UPDATE users %s WHERE id = '%s'", setSQL, updUser.ID)
+	args = append(args, updUser.ID)
+	q := fmt.Sprintf("UPDATE users %s WHERE id = $%d AND deleted_at ISNULL", setSQL, len(args))
 
-	_, err := r.db.Exec(ctx, q, args...)
+	res, err := r.db.Exec(ctx, q, args...)
 	if err != nil {
+		if isUniqueViolation(err, "users_passport_uniq_idx") {
+			return &UserExistsError{}
+		}
 		return err
 	}
 
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
 	return nil
 }
 
@@ -53,11 +118,11 @@ func setUpdateProductSQL(updUser UpdateUser) (string, []any) {
 
 	if updUser.PassportSeries != nil {
 		args = append(args, *updUser.PassportSeries)
-		cols = append(cols, fmt.Sprintf("pasport_series = $%d", len(args)))
+		cols = append(cols, fmt.Sprintf("passport_series = $%d", len(args)))
 	}
 	if updUser.PassportNumber != nil {
 		args = append(args, *updUser.PassportNumber)
-		cols = append(cols, fmt.Sprintf("pasport_number = $%d", len(args)))
+		cols = append(cols, fmt.Sprintf("passport_number = $%d", len(args)))
 	}
 	if updUser.Surname != nil {
 		args = append(args, *updUser.Surname)
@@ -75,15 +140,36 @@ func setUpdateProductSQL(updUser UpdateUser) (string, []any) {
 		args = append(args, *updUser.Address)
 		cols = append(cols, fmt.Sprintf("address = $%d", len(args)))
 	}
+	if updUser.Surname != nil || updUser.Name != nil || updUser.Patronymic != nil || updUser.Address != nil {
+		// person info set by hand ends the enrichment, so a later claim can't overwrite it
+		cols = append(cols, "enrichment_status = 'done'", "enrichment_error = ''", "enrichment_next_at = NULL")
+	}
+	if updUser.TimeZone != nil {
+		args = append(args, *updUser.TimeZone)
+		cols = append(cols, fmt.Sprintf("time_zone = $%d", len(args)))
+	}
+
+	if len(cols) > 0 {
+		cols = append(cols, "version = version + 1")
+	}
 
 	return "SET " + strings.Join(cols, ", "), args
 }
 
-func (r *Repository) UserByID(ctx context.Context, id uuid.UUID) (u User, err error) {
+func (r *Repository) UserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	return r.userByID(ctx, id, "")
+}
+
+// LockUser is UserByID that also locks the user row until the end of the transaction.
+func (r *Repository) LockUser(ctx context.Context, id uuid.UUID) (User, error) {
+	return r.userByID(ctx, id, "FOR UPDATE")
+}
+
+func (r *Repository) userByID(ctx context.Context, id uuid.UUID, lock string) (u User, err error) {
 	q := `
-SELECT id, passport_series, passport_number, surname, name, patronymic, address 
+SELECT id, passport_series, passport_number, surname, name, patronymic, address, time_zone, enrichment_status, enrichment_error, version, created_at
 FROM users WHERE id = $1 AND deleted_at ISNULL
-`
+` + lock
 
 	err = r.db.QueryRow(ctx, q, id).Scan(
 		&u.ID,
@@ -93,6 +179,11 @@ FROM users WHERE id = $1 AND deleted_at ISNULL
 		&u.Name,
 		&u.Patronymic,
 		&u.Address,
+		&u.TimeZone,
+		&u.EnrichmentStatus,
+		&u.EnrichmentError,
+		&u.Version,
+		&u.CreatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -104,8 +195,158 @@ FROM users WHERE id = $1 AND deleted_at ISNULL
 	return u, nil
 }
 
+func (r *Repository) CreateUserRevision(ctx context.Context, rev UserRevision) error {
+	q := `
+INSERT INTO user_revisions (id, user_id, action, changes, actor, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+	_, err := r.db.Exec(ctx, q, rev.ID, rev.UserID, rev.Action, rev.Changes, rev.Actor, rev.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) UserRevisions(ctx context.Context, userID uuid.UUID) ([]UserRevision, error) {
+	var exists bool
+
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, userID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	q := `
+SELECT id, user_id, action, changes, actor, created_at
+FROM user_revisions WHERE user_id = $1 ORDER BY created_at DESC
+`
+
+	rows, err := r.db.Query(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []UserRevision{}
+
+	for rows.Next() {
+		var rev UserRevision
+		err = rows.Scan(&rev.ID, &rev.UserID, &rev.Action, &rev.Changes, &rev.Actor, &rev.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, rev)
+	}
+
+	return revisions, rows.Err()
+}
+
+// ClaimEnrichments picks pending users that are due and hides them from other
+// workers until the lease expires, so several replicas can run the worker.
+func (r *Repository) ClaimEnrichments(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Enrichment, error) {
+	q := `
+UPDATE users SET enrichment_next_at = $2
+WHERE id IN (
+    SELECT id FROM users
+    WHERE enrichment_status = 'pending' AND enrichment_next_at <= $1 AND deleted_at ISNULL
+    ORDER BY enrichment_next_at LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, passport_series, passport_number, enrichment_attempts, version
+`
+
+	rows, err := r.db.Query(ctx, q, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var enrichments []Enrichment
+
+	for rows.Next() {
+		var e Enrichment
+		err = rows.Scan(&e.UserID, &e.PassportSeries, &e.PassportNumber, &e.Attempts, &e.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		enrichments = append(enrichments, e)
+	}
+
+	return enrichments, rows.Err()
+}
+
+// CompleteEnrichment saves the person info of a claimed user, it fails with
+// ErrVersionMismatch if the user has been changed or deleted since the claim.
+func (r *Repository) CompleteEnrichment(ctx context.Context, e Enrichment, info PersonInfo) error {
+	q := `
+UPDATE users
+SET surname = $1, name = $2, patronymic = $3, address = $4,
+    enrichment_status = 'done', enrichment_error = '', enrichment_attempts = enrichment_attempts + 1, enrichment_next_at = NULL,
+    version = version + 1
+WHERE id = $5 AND version = $6 AND deleted_at ISNULL
+`
+
+	res, err := r.db.Exec(ctx, q, info.Surname, info.Name, info.Patronymic, info.Address, e.UserID, e.Version)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// FailEnrichment records a failed attempt. A nil nextAt means the user won't
+// be retried until RetryEnrichment is called.
+func (r *Repository) FailEnrichment(ctx context.Context, id uuid.UUID, enrichErr string, nextAt *time.Time) error {
+	q := `
+UPDATE users
+SET enrichment_status = CASE WHEN $2::TIMESTAMPTZ ISNULL THEN 'failed' ELSE 'pending' END,
+    enrichment_error = $1, enrichment_attempts = enrichment_attempts + 1, enrichment_next_at = $2
+WHERE id = $3
+`
+
+	_, err := r.db.Exec(ctx, q, enrichErr, nextAt, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) RetryEnrichment(ctx context.Context, id uuid.UUID, now time.Time) error {
+	q := `
+UPDATE users SET enrichment_status = 'pending', enrichment_attempts = 0, enrichment_next_at = $1
+WHERE id = $2 AND enrichment_status = 'failed' AND deleted_at ISNULL
+`
+
+	res, err := r.db.Exec(ctx, q, now, id)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		_, err = r.UserByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return ErrEnrichmentNotFailed
+	}
+
+	return nil
+}
+
 func (r *Repository) DeleteUser(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
-	q := `UPDATE users SET deleted_at = $1 WHERE id = $2 AND deleted_at ISNULL`
+	q := `UPDATE users SET deleted_at = $1, version = version + 1 WHERE id = $2 AND deleted_at ISNULL`
 
 	res, err := r.db.Exec(ctx, q, deletedAt, id)
 	if err != nil {
@@ -119,41 +360,128 @@ func (r *Repository) DeleteUser(ctx context.Context, id uuid.UUID, deletedAt tim
 	return nil
 }
 
+// RestoreUser undeletes the user and returns when they were deleted.
+func (r *Repository) RestoreUser(ctx context.Context, id uuid.UUID) (deletedAt time.Time, err error) {
+	q := `
+UPDATE users u SET deleted_at = NULL, version = u.version + 1
+FROM users old
+WHERE u.id = $1 AND old.id = u.id AND u.deleted_at IS NOT NULL
+RETURNING old.deleted_at
+`
+
+	err = r.db.QueryRow(ctx, q, id).Scan(&deletedAt)
+	if err != nil {
+		if isUniqueViolation(err, "users_passport_uniq_idx") {
+			return time.Time{}, &UserExistsError{}
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, err
+		}
+
+		_, err = r.UserByID(ctx, id)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Time{}, ErrNotDeleted
+	}
+
+	return deletedAt, nil
+}
+
+// PurgeUser removes the user, deleted or not, with all their work hours and history.
+func (r *Repository) PurgeUser(ctx context.Context, id uuid.UUID) error {
+	q := `DELETE FROM work_pauses WHERE work_hours_id IN (SELECT id FROM work_hours WHERE user_id = $1)`
+
+	_, err := r.db.Exec(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	q = `DELETE FROM work_hours WHERE user_id = $1`
+
+	_, err = r.db.Exec(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	q = `DELETE FROM user_revisions WHERE user_id = $1`
+
+	_, err = r.db.Exec(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	q = `DELETE FROM users WHERE id = $1`
+
+	res, err := r.db.Exec(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// StartWork opens a session, ErrWorkAlreadyStarted if the user already has
+// an open session on the task.
 func (r *Repository) StartWork(ctx context.Context, wh WorkHours) error {
 	q := `
-INSERT INTO work_hours (user_id, task_id, started_at)
-VALUES ($1, $2, $3)
+INSERT INTO work_hours (id, user_id, task_id, started_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, task_id) WHERE finished_at ISNULL DO NOTHING
 `
 
-	_, err := r.db.Exec(ctx, q, wh.UserID, wh.TaskID, wh.StartedAt)
+	res, err := r.db.Exec(ctx, q, wh.ID, wh.UserID, wh.TaskID, wh.StartedAt)
 	if err != nil {
 		return err
 	}
 
+	if res.RowsAffected() == 0 {
+		return ErrWorkAlreadyStarted
+	}
+
 	return nil
 }
 
 func (r *Repository) FinishWork(ctx context.Context, wh WorkHours) error {
 	q := `
 UPDATE work_hours
-SET finished_at = $1, spend_time_sec = $2
-WHERE user_id = $3 AND task_id = $4 AND finished_at ISNULL
+SET finished_at = $1, spend_time_sec = $2, auto_closed = $3
+WHERE id = $4 AND finished_at ISNULL
 `
 
-	_, err := r.db.Exec(ctx, q, wh.FinishedAt, wh.SpendTimeSec, wh.UserID, wh.TaskID)
+	res, err := r.db.Exec(ctx, q, wh.FinishedAt, wh.SpendTimeSec, wh.AutoClosed, wh.ID)
 	if err != nil {
 		return err
 	}
 
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
 	return nil
 }
 
-func (r *Repository) NotFinishedWorkHours(ctx context.Context, userID uuid.UUID, taskID uuid.UUID) (wh WorkHours, err error) {
-	q := `SELECT user_id, task_id, started_at, finished_at, spend_time_sec
+func (r *Repository) NotFinishedWorkHours(ctx context.Context, userID uuid.UUID, taskID uuid.UUID) (WorkHours, error) {
+	return r.notFinishedWorkHours(ctx, userID, taskID, "")
+}
+
+// LockNotFinishedWorkHours is NotFinishedWorkHours that locks the session
+// until the end of the transaction.
+func (r *Repository) LockNotFinishedWorkHours(ctx context.Context, userID uuid.UUID, taskID uuid.UUID) (WorkHours, error) {
+	return r.notFinishedWorkHours(ctx, userID, taskID, "FOR UPDATE")
+}
+
+func (r *Repository) notFinishedWorkHours(ctx context.Context, userID uuid.UUID, taskID uuid.UUID, lock string) (wh WorkHours, err error) {
+	q := `SELECT id, user_id, task_id, started_at, finished_at, spend_time_sec, auto_closed
 FROM work_hours
-WHERE user_id = $1 AND task_id = $2 AND finished_at ISNULL`
+WHERE user_id = $1 AND task_id = $2 AND finished_at ISNULL
+` + lock
 
-	err = r.db.QueryRow(ctx, q, userID, taskID).Scan(&wh.UserID, &wh.TaskID, &wh.StartedAt, &wh.FinishedAt, &wh.SpendTimeSec)
+	err = r.db.QueryRow(ctx, q, userID, taskID).Scan(&wh.ID, &wh.UserID, &wh.TaskID, &wh.StartedAt, &wh.FinishedAt, &wh.SpendTimeSec, &wh.AutoClosed)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return WorkHours{}, ErrNotFound
@@ -164,109 +492,1134 @@ WHERE user_id = $1 AND task_id = $2 AND finished_at ISNULL`
 	return wh, nil
 }
 
-func (r *Repository) TaskSpendTimesByUser(ctx context.Context, id uuid.UUID, period Period) ([]TaskSpendTime, error) {
-	q := `
-SELECT task_id, SUM(spend_time_sec) sum_spend_time_sec FROM work_hours
-WHERE user_id = $1 AND finished_at IS NOT NULL AND finished_at BETWEEN $2 AND $3
-GROUP BY task_id ORDER BY sum_spend_time_sec DESC 
-`
+// ClaimOverdueWorkHours locks running sessions started before startedBefore,
+// rows locked by another replica are skipped.
+func (r *Repository) ClaimOverdueWorkHours(ctx context.Context, startedBefore time.Time, limit int) ([]WorkHours, error) {
+	q := `SELECT id, user_id, task_id, started_at, finished_at, spend_time_sec, auto_closed
+FROM work_hours
+WHERE finished_at ISNULL AND started_at < $1
+ORDER BY started_at LIMIT $2
+FOR UPDATE SKIP LOCKED`
 
-	rows, err := r.db.Query(ctx, q, id, period.StartDate, period.EndDate)
+	rows, err := r.db.Query(ctx, q, startedBefore, limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var taskSpendTimes []TaskSpendTime
+	var workHours []WorkHours
 
 	for rows.Next() {
-		var taskSpendTime TaskSpendTime
+		var wh WorkHours
 
-		err = rows.Scan(
-			&taskSpendTime.TaskID,
-			&taskSpendTime.SpendTimeSec,
-		)
+		err = rows.Scan(&wh.ID, &wh.UserID, &wh.TaskID, &wh.StartedAt, &wh.FinishedAt, &wh.SpendTimeSec, &wh.AutoClosed)
 		if err != nil {
 			return nil, err
 		}
 
-		taskSpendTime.UserID = id
-
-		taskSpendTimes = append(taskSpendTimes, taskSpendTime)
+		workHours = append(workHours, wh)
 	}
 
-	return taskSpendTimes, rows.Err()
+	return workHours, rows.Err()
 }
 
-func (r *Repository) Users(ctx context.Context, page, perPage int, filter UserFilter) ([]User, error) {
-	offset := 0
-	if page > 1 {
-		offset = (page - 1) * perPage
-	}
-
-	filterStr, filterArgs := setFilter(filter)
-
-	q := fmt.Sprintf(`SELECT id, passport_series, passport_number, surname, name, patronymic, address, created_at
-FROM users WHERE %s deleted_at ISNULL ORDER BY created_at DESC OFFSET %d LIMIT %d`, filterStr, offset, perPage)
+// LockUserNotFinishedWorkHours locks all running sessions of the user.
+func (r *Repository) LockUserNotFinishedWorkHours(ctx context.Context, userID uuid.UUID) ([]WorkHours, error) {
+	q := `SELECT id, user_id, task_id, started_at, finished_at, spend_time_sec, auto_closed
+FROM work_hours
+WHERE user_id = $1 AND finished_at ISNULL
+ORDER BY started_at
+FOR UPDATE`
 
-	rows, err := r.db.Query(ctx, q, filterArgs...)
+	rows, err := r.db.Query(ctx, q, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var users []User
+	var workHours []WorkHours
 
 	for rows.Next() {
-		var user User
-		err = rows.Scan(
-			&user.ID,
-			&user.PassportSeries,
-			&user.PassportNumber,
-			&user.Surname,
-			&user.Name,
-			&user.Patronymic,
-			&user.Address,
-			&user.CreatedAt,
-		)
+		var wh WorkHours
+
+		err = rows.Scan(&wh.ID, &wh.UserID, &wh.TaskID, &wh.StartedAt, &wh.FinishedAt, &wh.SpendTimeSec, &wh.AutoClosed)
 		if err != nil {
 			return nil, err
 		}
 
-		users = append(users, user)
+		workHours = append(workHours, wh)
 	}
 
-	return users, rows.Err()
+	return workHours, rows.Err()
 }
 
-func setFilter(filter UserFilter) (string, []any) {
-	var q string
-	var args []any
+func (r *Repository) PauseWork(ctx context.Context, p WorkPause) error {
+	q := `
+INSERT INTO work_pauses (id, work_hours_id, paused_at)
+VALUES ($1, $2, $3)
+`
 
-	if filter.ID != nil {
-		q = fmt.Sprintf("id = '%s' AND", filter.ID)
-	}
-	if filter.PassportSeries != nil {
-		q = fmt.Sprintf("passport_series = %d AND", *filter.PassportSeries)
-	}
-	if filter.PassportNumber != nil {
-		q = fmt.Sprintf("passport_number = %d AND", *filter.PassportNumber)
+	_, err := r.db.Exec(ctx, q, p.ID, p.WorkHoursID, p.PausedAt)
+	if err != nil {
+		if isUniqueViolation(err, "work_pauses_open_uniq_idx") {
+			return ErrWorkPaused
+		}
+		return err
 	}
-	if filter.Surname != nil {
-		args = append(args, *filter.Surname)
-		q = fmt.Sprintf("surname = $%d AND", len(args))
+
+	return nil
+}
+
+// ResumeWork closes the open pause of the session, ErrWorkNotPaused if there is none.
+func (r *Repository) ResumeWork(ctx context.Context, workHoursID uuid.UUID, resumedAt time.Time) error {
+	q := `
+UPDATE work_pauses
+SET resumed_at = GREATEST($1, paused_at)
+WHERE work_hours_id = $2 AND resumed_at ISNULL
+`
+
+	res, err := r.db.Exec(ctx, q, resumedAt, workHoursID)
+	if err != nil {
+		return err
 	}
-	if filter.Name != nil {
-		args = append(args, *filter.Name)
-		q = fmt.Sprintf("name = $%d AND", len(args))
+
+	if res.RowsAffected() == 0 {
+		return ErrWorkNotPaused
 	}
-	if filter.Patronymic != nil {
-		args = append(args, *filter.Patronymic)
-		q = fmt.Sprintf("patronymic = $%d AND", len(args))
+
+	return nil
+}
+
+// PausedSec sums the pauses of a session clipped to [since, until],
+// open pauses last until the end.
+func (r *Repository) PausedSec(ctx context.Context, workHoursID uuid.UUID, since time.Time, until time.Time) (int, error) {
+	q := `
+SELECT COALESCE(SUM(EXTRACT(EPOCH FROM LEAST(COALESCE(resumed_at, $3), $3) - GREATEST(paused_at, $2))), 0)::INTEGER
+FROM work_pauses
+WHERE work_hours_id = $1 AND paused_at < $3 AND COALESCE(resumed_at, $3) > $2
+`
+
+	var sec int
+
+	err := r.db.QueryRow(ctx, q, workHoursID, since, until).Scan(&sec)
+	if err != nil {
+		return 0, err
 	}
-	if filter.Address != nil {
-		args = append(args, *filter.Address)
-		q = fmt.Sprintf("address = $%d AND", len(args))
+
+	return sec, nil
+}
+
+func (r *Repository) DeleteWorkPauses(ctx context.Context, workHoursID uuid.UUID) error {
+	q := `DELETE FROM work_pauses WHERE work_hours_id = $1`
+
+	_, err := r.db.Exec(ctx, q, workHoursID)
+	if err != nil {
+		return err
 	}
-	return q, args
+
+	return nil
+}
+
+func (r *Repository) CreateWorkHours(ctx context.Context, wh WorkHours) error {
+	q := `
+INSERT INTO work_hours (id, user_id, task_id, started_at, finished_at, spend_time_sec)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+	_, err := r.db.Exec(ctx, q, wh.ID, wh.UserID, wh.TaskID, wh.StartedAt, wh.FinishedAt, wh.SpendTimeSec)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) WorkHoursByID(ctx context.Context, id uuid.UUID) (WorkHours, error) {
+	return r.workHoursByID(ctx, id, "")
+}
+
+// LockWorkHours is WorkHoursByID that locks the entry until the end of the transaction.
+func (r *Repository) LockWorkHours(ctx context.Context, id uuid.UUID) (WorkHours, error) {
+	return r.workHoursByID(ctx, id, "FOR UPDATE")
+}
+
+func (r *Repository) workHoursByID(ctx context.Context, id uuid.UUID, lock string) (wh WorkHours, err error) {
+	q := `SELECT id, user_id, task_id, started_at, finished_at, spend_time_sec, auto_closed
+FROM work_hours
+WHERE id = $1
+` + lock
+
+	err = r.db.QueryRow(ctx, q, id).Scan(&wh.ID, &wh.UserID, &wh.TaskID, &wh.StartedAt, &wh.FinishedAt, &wh.SpendTimeSec, &wh.AutoClosed)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return WorkHours{}, ErrNotFound
+		}
+		return WorkHours{}, err
+	}
+
+	return wh, nil
+}
+
+// workEntryFilterSQL takes WorkEntryFilter fields as $1..$7, running sessions
+// are compared by the time elapsed since their start.
+const workEntryFilterSQL = `
+($1::UUID ISNULL OR user_id = $1)
+AND ($2::UUID ISNULL OR task_id = $2)
+AND ($3::TIMESTAMPTZ ISNULL OR finished_at ISNULL OR finished_at > $3)
+AND ($4::TIMESTAMPTZ ISNULL OR started_at < $4)
+AND ($5::BOOLEAN ISNULL OR (finished_at ISNULL) = $5)
+AND ($6::INTEGER ISNULL OR CASE WHEN finished_at ISNULL THEN EXTRACT(EPOCH FROM now() - started_at) ELSE spend_time_sec END >= $6)
+AND ($7::BOOLEAN ISNULL OR auto_closed = $7)
+`
+
+func workEntryFilterArgs(filter WorkEntryFilter) []any {
+	return []any{filter.UserID, filter.TaskID, filter.From, filter.To, filter.Open, filter.MinDurationSec, filter.AutoClosed}
+}
+
+func (r *Repository) WorkEntries(ctx context.Context, page WorkEntryPage, filter WorkEntryFilter) ([]WorkHours, error) {
+	q := fmt.Sprintf(`
+SELECT id, user_id, task_id, started_at, finished_at, spend_time_sec, auto_closed
+FROM work_hours
+WHERE %s
+ORDER BY started_at DESC, id DESC
+OFFSET $8 LIMIT $9
+`, workEntryFilterSQL)
+
+	args := append(workEntryFilterArgs(filter), (page.Page-1)*page.PerPage, page.PerPage)
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	workHours := make([]WorkHours, 0)
+
+	for rows.Next() {
+		var wh WorkHours
+
+		err = rows.Scan(&wh.ID, &wh.UserID, &wh.TaskID, &wh.StartedAt, &wh.FinishedAt, &wh.SpendTimeSec, &wh.AutoClosed)
+		if err != nil {
+			return nil, err
+		}
+
+		workHours = append(workHours, wh)
+	}
+
+	return workHours, rows.Err()
+}
+
+func (r *Repository) CountWorkEntries(ctx context.Context, filter WorkEntryFilter) (total int, err error) {
+	q := fmt.Sprintf(`SELECT COUNT(*) FROM work_hours WHERE %s`, workEntryFilterSQL)
+
+	err = r.db.QueryRow(ctx, q, workEntryFilterArgs(filter)...).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *Repository) UpdateWorkHours(ctx context.Context, wh WorkHours) error {
+	q := `
+UPDATE work_hours
+SET task_id = $1, started_at = $2, finished_at = $3, spend_time_sec = $4, auto_closed = $5
+WHERE id = $6
+`
+
+	res, err := r.db.Exec(ctx, q, wh.TaskID, wh.StartedAt, wh.FinishedAt, wh.SpendTimeSec, wh.AutoClosed, wh.ID)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteWorkHours(ctx context.Context, id uuid.UUID) error {
+	q := `DELETE FROM work_hours WHERE id = $1`
+
+	res, err := r.db.Exec(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// WorkHoursOverlap reports whether the user has another entry intersecting
+// [startedAt, finishedAt), running sessions are open-ended.
+func (r *Repository) WorkHoursOverlap(ctx context.Context, userID uuid.UUID, startedAt, finishedAt time.Time, exceptID uuid.UUID) (bool, error) {
+	q := `
+SELECT EXISTS (
+    SELECT 1 FROM work_hours
+    WHERE user_id = $1 AND id <> $4 AND started_at < $3 AND (finished_at ISNULL OR finished_at > $2)
+)
+`
+
+	var overlap bool
+
+	err := r.db.QueryRow(ctx, q, userID, startedAt, finishedAt, exceptID).Scan(&overlap)
+	if err != nil {
+		return false, err
+	}
+
+	return overlap, nil
+}
+
+// ActiveWork returns running sessions of all users or of userID, elapsed time is counted up to now.
+func (r *Repository) ActiveWork(ctx context.Context, userID *uuid.UUID, now time.Time) ([]ActiveWork, error) {
+	q := `
+SELECT wh.id, wh.user_id, u.surname, u.name, u.patronymic, wh.task_id, t.title, wh.started_at,
+    EXISTS (SELECT 1 FROM work_pauses wp WHERE wp.work_hours_id = wh.id AND wp.resumed_at ISNULL) paused,
+    GREATEST(EXTRACT(EPOCH FROM $2 - wh.started_at) - COALESCE((
+        SELECT SUM(EXTRACT(EPOCH FROM COALESCE(wp.resumed_at, $2) - wp.paused_at))
+        FROM work_pauses wp WHERE wp.work_hours_id = wh.id
+    ), 0), 0)::INTEGER elapsed_sec
+FROM work_hours wh
+    JOIN users u ON u.id = wh.user_id
+    JOIN tasks t ON t.id = wh.task_id
+WHERE wh.finished_at ISNULL AND ($1::UUID ISNULL OR wh.user_id = $1)
+ORDER BY wh.started_at
+`
+
+	rows, err := r.db.Query(ctx, q, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	active := make([]ActiveWork, 0)
+
+	for rows.Next() {
+		var a ActiveWork
+		err = rows.Scan(
+			&a.ID,
+			&a.UserID,
+			&a.Surname,
+			&a.Name,
+			&a.Patronymic,
+			&a.TaskID,
+			&a.TaskTitle,
+			&a.StartedAt,
+			&a.Paused,
+			&a.ElapsedSec,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		active = append(active, a)
+	}
+
+	return active, rows.Err()
+}
+
+// clippedWorkHoursSQL selects bucket number n, task_id and spend_sec of the
+// sessions of user $1 clipped to each bucket [$2[n], $3[n]), pauses within the
+// clipped interval excluded. Running sessions count up to $4 when it's not null.
+const clippedWorkHoursSQL = `
+SELECT bk.n, wh.task_id,
+    EXTRACT(EPOCH FROM b.hi - b.lo) - COALESCE((
+        SELECT SUM(EXTRACT(EPOCH FROM LEAST(COALESCE(wp.resumed_at, b.hi), b.hi) - GREATEST(wp.paused_at, b.lo)))
+        FROM work_pauses wp
+        WHERE wp.work_hours_id = wh.id AND wp.paused_at < b.hi AND COALESCE(wp.resumed_at, b.hi) > b.lo
+    ), 0) spend_sec
+FROM unnest($2::TIMESTAMPTZ[], $3::TIMESTAMPTZ[]) WITH ORDINALITY bk (start, finish, n)
+    JOIN work_hours wh ON wh.user_id = $1 AND (wh.finished_at IS NOT NULL OR $4::TIMESTAMPTZ IS NOT NULL)
+        AND wh.started_at < bk.finish AND COALESCE(wh.finished_at, $4) > bk.start
+    CROSS JOIN LATERAL (
+        SELECT GREATEST(wh.started_at, bk.start) lo, LEAST(COALESCE(wh.finished_at, $4), bk.finish) hi
+    ) b
+`
+
+// TaskSpendTimesByBuckets returns task spend times of every bucket [starts[i], ends[i]).
+func (r *Repository) TaskSpendTimesByBuckets(ctx context.Context, id uuid.UUID, starts, ends []time.Time, runningUntil *time.Time) ([][]TaskSpendTime, error) {
+	q := fmt.Sprintf(`
+SELECT wh.n, t.id, t.title, p.id, p.name, c.id, c.name, ROUND(SUM(wh.spend_sec))::INTEGER sum_spend_time_sec
+FROM (%s) wh
+    JOIN tasks t ON t.id = wh.task_id
+    JOIN projects p ON p.id = t.project_id
+    JOIN clients c ON c.id = p.client_id
+GROUP BY wh.n, t.id, t.title, p.id, p.name, c.id, c.name ORDER BY wh.n, sum_spend_time_sec DESC
+`, clippedWorkHoursSQL)
+
+	rows, err := r.db.Query(ctx, q, id, starts, ends, runningUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := make([][]TaskSpendTime, len(starts))
+
+	for rows.Next() {
+		var n int
+		var taskSpendTime TaskSpendTime
+
+		err = rows.Scan(
+			&n,
+			&taskSpendTime.TaskID,
+			&taskSpendTime.TaskTitle,
+			&taskSpendTime.ProjectID,
+			&taskSpendTime.ProjectName,
+			&taskSpendTime.ClientID,
+			&taskSpendTime.ClientName,
+			&taskSpendTime.SpendTimeSec,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		taskSpendTime.UserID = id
+
+		// ordinality starts at 1
+		buckets[n-1] = append(buckets[n-1], taskSpendTime)
+	}
+
+	return buckets, rows.Err()
+}
+
+func (r *Repository) TaskSpendTimesByUser(ctx context.Context, id uuid.UUID, period Period) ([]TaskSpendTime, error) {
+	q := fmt.Sprintf(`
+SELECT t.id, t.title, p.id, p.name, c.id, c.name, ROUND(SUM(wh.spend_sec))::INTEGER sum_spend_time_sec
+FROM (%s) wh
+    JOIN tasks t ON t.id = wh.task_id
+    JOIN projects p ON p.id = t.project_id
+    JOIN clients c ON c.id = p.client_id
+GROUP BY t.id, t.title, p.id, p.name, c.id, c.name ORDER BY sum_spend_time_sec DESC
+`, clippedWorkHoursSQL)
+
+	rows, err := r.db.Query(ctx, q, id, []time.Time{period.StartDate}, []time.Time{period.EndDate}, period.RunningUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var taskSpendTimes []TaskSpendTime
+
+	for rows.Next() {
+		var taskSpendTime TaskSpendTime
+
+		err = rows.Scan(
+			&taskSpendTime.TaskID,
+			&taskSpendTime.TaskTitle,
+			&taskSpendTime.ProjectID,
+			&taskSpendTime.ProjectName,
+			&taskSpendTime.ClientID,
+			&taskSpendTime.ClientName,
+			&taskSpendTime.SpendTimeSec,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		taskSpendTime.UserID = id
+
+		taskSpendTimes = append(taskSpendTimes, taskSpendTime)
+	}
+
+	return taskSpendTimes, rows.Err()
+}
+
+// Users returns up to page.PerPage+1 users, the extra one tells there is a next page.
+func (r *Repository) Users(ctx context.Context, page UserPage, filter UserFilter) ([]User, error) {
+	offset := 0
+	if page.Page > 1 {
+		offset = (page.Page - 1) * page.PerPage
+	}
+
+	filterStr, args := setFilter(filter)
+
+	orderCols := userSortColumns(page.Sort)
+
+	if page.After != nil {
+		if page.After.Sort != userSortKey(page.Sort) || len(page.After.Values) != len(orderCols)-1 {
+			return nil, ErrCursorMismatch
+		}
+
+		offset = 0
+
+		var afterStr string
+		afterStr, args = setAfter(orderCols, append(page.After.Values, page.After.ID.String()), args)
+		filterStr += " AND " + afterStr
+	}
+
+	var order []string
+	for _, col := range orderCols {
+		dir := "ASC"
+		if col.desc {
+			dir = "DESC"
+		}
+		order = append(order, col.name+" "+dir)
+	}
+
+	args = append(args, offset, page.PerPage+1)
+	q := fmt.Sprintf(`SELECT id, passport_series, passport_number, surname, name, patronymic, address, time_zone, enrichment_status, enrichment_error, version, created_at, deleted_at
+FROM users WHERE %s ORDER BY %s OFFSET $%d LIMIT $%d`, filterStr, strings.Join(order, ", "), len(args)-1, len(args))
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []User
+
+	for rows.Next() {
+		var user User
+		err = rows.Scan(
+			&user.ID,
+			&user.PassportSeries,
+			&user.PassportNumber,
+			&user.Surname,
+			&user.Name,
+			&user.Patronymic,
+			&user.Address,
+			&user.TimeZone,
+			&user.EnrichmentStatus,
+			&user.EnrichmentError,
+			&user.Version,
+			&user.CreatedAt,
+			&user.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+type sortColumn struct {
+	name string
+	// typ is the SQL type cursor values are cast to.
+	typ  string
+	desc bool
+}
+
+// userSortFields is the whitelist of sort fields with their columns.
+var userSortFields = map[string][]sortColumn{
+	SortBySurname:   {{name: "surname", typ: "TEXT"}},
+	SortByName:      {{name: "name", typ: "TEXT"}},
+	SortByCreatedAt: {{name: "created_at", typ: "TIMESTAMP"}},
+	SortByPassport:  {{name: "passport_series", typ: "INTEGER"}, {name: "passport_number", typ: "INTEGER"}},
+}
+
+// checkValue reports whether a cursor value can be cast to the column type,
+// so a tampered cursor doesn't fail the query.
+func (c sortColumn) checkValue(v string) error {
+	var err error
+
+	switch c.typ {
+	case "TEXT":
+		// JSON has already made it valid UTF-8, but Postgres text can't hold NUL
+		if strings.ContainsRune(v, 0) {
+			err = errors.New("text contains NUL")
+		}
+	case "TIMESTAMP":
+		_, err = time.Parse(time.RFC3339Nano, v)
+	case "INTEGER":
+		_, err = strconv.ParseInt(v, 10, 32)
+	case "UUID":
+		_, err = uuid.FromString(v)
+	}
+	if err != nil {
+		return fmt.Errorf("%s value %q: %w", c.name, v, err)
+	}
+
+	return nil
+}
+
+func isUserSortField(field string) bool {
+	_, ok := userSortFields[field]
+	return ok
+}
+
+// userSortColumns expands sort fields to columns with id as the last tiebreaker.
+func userSortColumns(sort []UserSort) []sortColumn {
+	var cols []sortColumn
+
+	for _, s := range sort {
+		for _, col := range userSortFields[s.Field] {
+			col.desc = s.Desc
+			cols = append(cols, col)
+		}
+	}
+
+	return append(cols, sortColumn{name: "id", typ: "UUID", desc: len(sort) > 0 && sort[0].Desc})
+}
+
+func userSortKey(sort []UserSort) string {
+	var keys []string
+
+	for _, s := range sort {
+		dir := "asc"
+		if s.Desc {
+			dir = "desc"
+		}
+		keys = append(keys, s.Field+":"+dir)
+	}
+
+	return strings.Join(keys, ",")
+}
+
+// newUserCursor returns the cursor pointing after u for the given sort.
+func newUserCursor(u User, sort []UserSort) UserCursor {
+	c := UserCursor{
+		Sort: userSortKey(sort),
+		ID:   u.ID,
+	}
+
+	for _, col := range userSortColumns(sort) {
+		switch col.name {
+		case "surname":
+			c.Values = append(c.Values, u.Surname)
+		case "name":
+			c.Values = append(c.Values, u.Name)
+		case "created_at":
+			c.Values = append(c.Values, u.CreatedAt.Format(time.RFC3339Nano))
+		case "passport_series":
+			c.Values = append(c.Values, strconv.Itoa(u.PassportSeries))
+		case "passport_number":
+			c.Values = append(c.Values, strconv.Itoa(u.PassportNumber))
+		}
+	}
+
+	return c
+}
+
+// setAfter returns the keyset condition for rows following values in the
+// order of cols, e.g. (a > $1) OR (a = $1 AND b < $2) for a ASC, b DESC.
+func setAfter(cols []sortColumn, values []string, args []any) (string, []any) {
+	var ors []string
+	var eqs []string
+
+	for i, col := range cols {
+		args = append(args, values[i])
+		param := fmt.Sprintf("$%d::%s", len(args), col.typ)
+
+		op := ">"
+		if col.desc {
+			op = "<"
+		}
+
+		ands := append(slices.Clone(eqs), fmt.Sprintf("%s %s %s", col.name, op, param))
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+
+		eqs = append(eqs, fmt.Sprintf("%s = %s", col.name, param))
+	}
+
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+func (r *Repository) CountUsers(ctx context.Context, filter UserFilter) (total int, err error) {
+	filterStr, args := setFilter(filter)
+
+	q := fmt.Sprintf(`SELECT COUNT(*) FROM users WHERE %s`, filterStr)
+
+	err = r.db.QueryRow(ctx, q, args...).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// userSearchSQL must match the expression of users_search_trgm_idx.
+const userSearchSQL = "(surname || ' ' || name || ' ' || patronymic || ' ' || address)"
+
+// setFilter returns the WHERE conditions for the filter joined with AND.
+func setFilter(filter UserFilter) (string, []any) {
+	var conds []string
+	var args []any
+
+	if !filter.IncludeDeleted {
+		conds = append(conds, "deleted_at ISNULL")
+	}
+	if filter.ID != nil {
+		args = append(args, *filter.ID)
+		conds = append(conds, fmt.Sprintf("id = $%d", len(args)))
+	}
+	if filter.PassportSeries != nil {
+		args = append(args, *filter.PassportSeries)
+		conds = append(conds, fmt.Sprintf("passport_series = $%d", len(args)))
+	}
+	if filter.PassportNumber != nil {
+		args = append(args, *filter.PassportNumber)
+		conds = append(conds, fmt.Sprintf("passport_number = $%d", len(args)))
+	}
+
+	textFilters := []struct {
+		col   string
+		value *string
+	}{
+		{"surname", filter.Surname},
+		{"name", filter.Name},
+		{"patronymic", filter.Patronymic},
+		{"address", filter.Address},
+	}
+
+	for _, f := range textFilters {
+		if f.value == nil {
+			continue
+		}
+
+		if filter.Match == MatchExact && !filter.IgnoreCase {
+			args = append(args, *f.value)
+			conds = append(conds, fmt.Sprintf("%s = $%d", f.col, len(args)))
+			continue
+		}
+
+		pattern := escapeLike(*f.value)
+		switch filter.Match {
+		case MatchPrefix:
+			pattern += "%"
+		case MatchContains:
+			pattern = "%" + pattern + "%"
+		}
+
+		op := "LIKE"
+		if filter.IgnoreCase {
+			op = "ILIKE"
+		}
+
+		args = append(args, pattern)
+		conds = append(conds, fmt.Sprintf("%s %s $%d", f.col, op, len(args)))
+	}
+
+	for _, word := range strings.Fields(filter.Query) {
+		args = append(args, "%"+escapeLike(word)+"%")
+		conds = append(conds, fmt.Sprintf("%s ILIKE $%d", userSearchSQL, len(args)))
+	}
+
+	if len(conds) == 0 {
+		return "TRUE", args
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+var likeReplacer = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeReplacer.Replace(s)
+}
+
+func (r *Repository) CreateTask(ctx context.Context, t Task) error {
+	q := `
+INSERT INTO tasks (id, project_id, title, description, created_at)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+	_, err := r.db.Exec(ctx, q, t.ID, t.ProjectID, t.Title, t.Description, t.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) TaskByID(ctx context.Context, id uuid.UUID) (Task, error) {
+	return r.taskByID(ctx, id, "")
+}
+
+// LockTask is TaskByID that also locks the task row against other locks until
+// the end of the transaction.
+func (r *Repository) LockTask(ctx context.Context, id uuid.UUID) (Task, error) {
+	return r.taskByID(ctx, id, "FOR UPDATE")
+}
+
+// ShareLockTask is TaskByID that keeps the task from being locked by LockTask,
+// updated or deleted until the end of the transaction.
+func (r *Repository) ShareLockTask(ctx context.Context, id uuid.UUID) (Task, error) {
+	return r.taskByID(ctx, id, "FOR SHARE")
+}
+
+func (r *Repository) taskByID(ctx context.Context, id uuid.UUID, lock string) (t Task, err error) {
+	q := `
+SELECT id, project_id, title, description, created_at, closed_at
+FROM tasks WHERE id = $1 AND deleted_at ISNULL
+` + lock
+
+	err = r.db.QueryRow(ctx, q, id).Scan(
+		&t.ID,
+		&t.ProjectID,
+		&t.Title,
+		&t.Description,
+		&t.CreatedAt,
+		&t.ClosedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Task{}, ErrNotFound
+		}
+		return Task{}, err
+	}
+
+	return t, nil
+}
+
+func (r *Repository) Tasks(ctx context.Context, filter TaskFilter) ([]Task, error) {
+	q := `
+SELECT id, project_id, title, description, created_at, closed_at
+FROM tasks WHERE deleted_at ISNULL AND ($1::UUID ISNULL OR project_id = $1) ORDER BY created_at DESC
+`
+
+	rows, err := r.db.Query(ctx, q, filter.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []Task
+
+	for rows.Next() {
+		var t Task
+		err = rows.Scan(
+			&t.ID,
+			&t.ProjectID,
+			&t.Title,
+			&t.Description,
+			&t.CreatedAt,
+			&t.ClosedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		tasks = append(tasks, t)
+	}
+
+	return tasks, rows.Err()
+}
+
+func (r *Repository) UpdateTask(ctx context.Context, id uuid.UUID, updTask UpdateTask, now time.Time) error {
+	var cols []string
+	var args []any
+
+	if updTask.ProjectID != nil {
+		args = append(args, *updTask.ProjectID)
+		cols = append(cols, fmt.Sprintf("project_id = $%d", len(args)))
+	}
+	if updTask.Title != nil {
+		args = append(args, *updTask.Title)
+		cols = append(cols, fmt.Sprintf("title = $%d", len(args)))
+	}
+	if updTask.Description != nil {
+		args = append(args, *updTask.Description)
+		cols = append(cols, fmt.Sprintf("description = $%d", len(args)))
+	}
+	if updTask.Closed != nil {
+		if *updTask.Closed {
+			args = append(args, now)
+			cols = append(cols, fmt.Sprintf("closed_at = COALESCE(closed_at, $%d)", len(args)))
+		} else {
+			cols = append(cols, "closed_at = NULL")
+		}
+	}
+
+	if len(cols) == 0 {
+		_, err := r.TaskByID(ctx, id)
+		return err
+	}
+
+	args = append(args, id)
+	q := fmt.Sprintf("UPDATE tasks SET %s WHERE id = $%d AND deleted_at ISNULL", strings.Join(cols, ", "), len(args))
+
+	res, err := r.db.Exec(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteTask(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	q := `UPDATE tasks SET deleted_at = $1 WHERE id = $2 AND deleted_at ISNULL`
+
+	res, err := r.db.Exec(ctx, q, deletedAt, id)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) CreateClient(ctx context.Context, c Client) error {
+	q := `INSERT INTO clients (id, name, created_at) VALUES ($1, $2, $3)`
+
+	_, err := r.db.Exec(ctx, q, c.ID, c.Name, c.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) ClientByID(ctx context.Context, id uuid.UUID) (c Client, err error) {
+	q := `SELECT id, name, created_at FROM clients WHERE id = $1 AND deleted_at ISNULL`
+
+	err = r.db.QueryRow(ctx, q, id).Scan(&c.ID, &c.Name, &c.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Client{}, ErrNotFound
+		}
+		return Client{}, err
+	}
+
+	return c, nil
+}
+
+func (r *Repository) Clients(ctx context.Context) ([]Client, error) {
+	q := `SELECT id, name, created_at FROM clients WHERE deleted_at ISNULL ORDER BY name`
+
+	rows, err := r.db.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clients []Client
+
+	for rows.Next() {
+		var c Client
+		err = rows.Scan(&c.ID, &c.Name, &c.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		clients = append(clients, c)
+	}
+
+	return clients, rows.Err()
+}
+
+func (r *Repository) UpdateClient(ctx context.Context, id uuid.UUID, updClient UpdateClient) error {
+	if updClient.Name == nil {
+		_, err := r.ClientByID(ctx, id)
+		return err
+	}
+
+	q := `UPDATE clients SET name = $1 WHERE id = $2 AND deleted_at ISNULL`
+
+	res, err := r.db.Exec(ctx, q, *updClient.Name, id)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteClient(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	q := `UPDATE clients SET deleted_at = $1 WHERE id = $2 AND deleted_at ISNULL`
+
+	res, err := r.db.Exec(ctx, q, deletedAt, id)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) CreateProject(ctx context.Context, p Project) error {
+	q := `INSERT INTO projects (id, client_id, name, created_at) VALUES ($1, $2, $3, $4)`
+
+	_, err := r.db.Exec(ctx, q, p.ID, p.ClientID, p.Name, p.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) ProjectByID(ctx context.Context, id uuid.UUID) (p Project, err error) {
+	q := `SELECT id, client_id, name, created_at FROM projects WHERE id = $1 AND deleted_at ISNULL`
+
+	err = r.db.QueryRow(ctx, q, id).Scan(&p.ID, &p.ClientID, &p.Name, &p.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Project{}, ErrNotFound
+		}
+		return Project{}, err
+	}
+
+	return p, nil
+}
+
+func (r *Repository) Projects(ctx context.Context, filter ProjectFilter) ([]Project, error) {
+	q := `
+SELECT id, client_id, name, created_at
+FROM projects WHERE deleted_at ISNULL AND ($1::UUID ISNULL OR client_id = $1) ORDER BY name
+`
+
+	rows, err := r.db.Query(ctx, q, filter.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []Project
+
+	for rows.Next() {
+		var p Project
+		err = rows.Scan(&p.ID, &p.ClientID, &p.Name, &p.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		projects = append(projects, p)
+	}
+
+	return projects, rows.Err()
+}
+
+func (r *Repository) UpdateProject(ctx context.Context, id uuid.UUID, updProject UpdateProject) error {
+	var cols []string
+	var args []any
+
+	if updProject.ClientID != nil {
+		args = append(args, *updProject.ClientID)
+		cols = append(cols, fmt.Sprintf("client_id = $%d", len(args)))
+	}
+	if updProject.Name != nil {
+		args = append(args, *updProject.Name)
+		cols = append(cols, fmt.Sprintf("name = $%d", len(args)))
+	}
+
+	if len(cols) == 0 {
+		_, err := r.ProjectByID(ctx, id)
+		return err
+	}
+
+	args = append(args, id)
+	q := fmt.Sprintf("UPDATE projects SET %s WHERE id = $%d AND deleted_at ISNULL", strings.Join(cols, ", "), len(args))
+
+	res, err := r.db.Exec(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteProject(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	q := `UPDATE projects SET deleted_at = $1 WHERE id = $2 AND deleted_at ISNULL`
+
+	res, err := r.db.Exec(ctx, q, deletedAt, id)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) ProjectSpendTimesByUser(ctx context.Context, id uuid.UUID, period Period) ([]ProjectSpendTime, error) {
+	q := fmt.Sprintf(`
+SELECT p.id, p.name, c.id, c.name, ROUND(SUM(wh.spend_sec))::INTEGER sum_spend_time_sec
+FROM (%s) wh
+    JOIN tasks t ON t.id = wh.task_id
+    JOIN projects p ON p.id = t.project_id
+    JOIN clients c ON c.id = p.client_id
+GROUP BY p.id, p.name, c.id, c.name ORDER BY sum_spend_time_sec DESC
+`, clippedWorkHoursSQL)
+
+	rows, err := r.db.Query(ctx, q, id, []time.Time{period.StartDate}, []time.Time{period.EndDate}, period.RunningUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projectSpendTimes []ProjectSpendTime
+
+	for rows.Next() {
+		var projectSpendTime ProjectSpendTime
+
+		err = rows.Scan(
+			&projectSpendTime.ProjectID,
+			&projectSpendTime.ProjectName,
+			&projectSpendTime.ClientID,
+			&projectSpendTime.ClientName,
+			&projectSpendTime.SpendTimeSec,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		projectSpendTime.UserID = id
+
+		projectSpendTimes = append(projectSpendTimes, projectSpendTime)
+	}
+
+	return projectSpendTimes, rows.Err()
+}
+
+func (r *Repository) ClientSpendTimesByUser(ctx context.Context, id uuid.UUID, period Period) ([]ClientSpendTime, error) {
+	q := fmt.Sprintf(`
+SELECT c.id, c.name, ROUND(SUM(wh.spend_sec))::INTEGER sum_spend_time_sec
+FROM (%s) wh
+    JOIN tasks t ON t.id = wh.task_id
+    JOIN projects p ON p.id = t.project_id
+    JOIN clients c ON c.id = p.client_id
+GROUP BY c.id, c.name ORDER BY sum_spend_time_sec DESC
+`, clippedWorkHoursSQL)
+
+	rows, err := r.db.Query(ctx, q, id, []time.Time{period.StartDate}, []time.Time{period.EndDate}, period.RunningUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clientSpendTimes []ClientSpendTime
+
+	for rows.Next() {
+		var clientSpendTime ClientSpendTime
+
+		err = rows.Scan(
+			&clientSpendTime.ClientID,
+			&clientSpendTime.ClientName,
+			&clientSpendTime.SpendTimeSec,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		clientSpendTime.UserID = id
+
+		clientSpendTimes = append(clientSpendTimes, clientSpendTime)
+	}
+
+	return clientSpendTimes, rows.Err()
 }
@@ -0,0 +1,68 @@
+package tracker
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSetAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     []UserSort
+		values   []string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "id only",
+			values:   []string{"a"},
+			wantSQL:  "((id > $2::UUID))",
+			wantArgs: []any{"filter", "a"},
+		},
+		{
+			name:     "two keys",
+			sort:     []UserSort{{Field: SortBySurname}},
+			values:   []string{"Ivanov", "a"},
+			wantSQL:  "((surname > $2::TEXT) OR (surname = $2::TEXT AND id > $3::UUID))",
+			wantArgs: []any{"filter", "Ivanov", "a"},
+		},
+		{
+			name:     "two keys descending",
+			sort:     []UserSort{{Field: SortByCreatedAt, Desc: true}},
+			values:   []string{"2024-08-26T10:00:00Z", "a"},
+			wantSQL:  "((created_at < $2::TIMESTAMP) OR (created_at = $2::TIMESTAMP AND id < $3::UUID))",
+			wantArgs: []any{"filter", "2024-08-26T10:00:00Z", "a"},
+		},
+		{
+			name:   "three keys",
+			sort:   []UserSort{{Field: SortByPassport, Desc: true}},
+			values: []string{"1234", "567890", "a"},
+			wantSQL: "((passport_series < $2::INTEGER)" +
+				" OR (passport_series = $2::INTEGER AND passport_number < $3::INTEGER)" +
+				" OR (passport_series = $2::INTEGER AND passport_number = $3::INTEGER AND id < $4::UUID))",
+			wantArgs: []any{"filter", "1234", "567890", "a"},
+		},
+		{
+			name:   "three keys with mixed directions",
+			sort:   []UserSort{{Field: SortBySurname}, {Field: SortByName, Desc: true}},
+			values: []string{"Ivanov", "Ivan", "a"},
+			wantSQL: "((surname > $2::TEXT)" +
+				" OR (surname = $2::TEXT AND name < $3::TEXT)" +
+				" OR (surname = $2::TEXT AND name = $3::TEXT AND id > $4::UUID))",
+			wantArgs: []any{"filter", "Ivanov", "Ivan", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := setAfter(userSortColumns(tt.sort), tt.values, []any{"filter"})
+
+			if sql != tt.wantSQL {
+				t.Errorf("setAfter SQL\n got: %s\nwant: %s", sql, tt.wantSQL)
+			}
+			if !slices.Equal(args, tt.wantArgs) {
+				t.Errorf("setAfter args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
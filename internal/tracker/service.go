@@ -1,12 +1,12 @@
 package tracker
 
 import (
+	"cmp"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
-	"net/http"
+	"slices"
 	"time"
 
 	"github.com/gofrs/uuid"
@@ -14,137 +14,1114 @@ import (
 
 var ErrNotFound = errors.New("not found")
 var ErrWorkAlreadyStarted = errors.New("work already started")
+var ErrTaskClosed = errors.New("task closed")
+var ErrInUse = errors.New("in use")
+var ErrEnrichmentNotFailed = errors.New("enrichment not failed")
+var ErrNotDeleted = errors.New("not deleted")
+var ErrCursorMismatch = errors.New("cursor does not match sort")
+var ErrAlreadyExists = errors.New("already exists")
+var ErrVersionMismatch = errors.New("version mismatch")
+var ErrWorkPaused = errors.New("work paused")
+var ErrWorkNotPaused = errors.New("work not paused")
+var ErrWorkNotFinished = errors.New("work not finished")
+var ErrWorkOverlap = errors.New("work overlaps another entry")
+var ErrInvalidWorkEntry = errors.New("invalid work entry")
+var ErrInvalidPeriod = errors.New("invalid period")
+
+// UserExistsError is ErrAlreadyExists for a user with the same passport,
+// ID is the existing user when it's known.
+type UserExistsError struct {
+	ID uuid.UUID
+}
+
+func (e *UserExistsError) Error() string {
+	if e.ID.IsNil() {
+		return "user with this passport already exists"
+	}
+	return fmt.Sprintf("user with this passport already exists: %s", e.ID)
+}
+
+func (e *UserExistsError) Is(target error) bool {
+	return target == ErrAlreadyExists
+}
 
 type Service struct {
-	repo   *Repository
-	client *http.Client
-	apiURL string
+	repo       *Repository
+	personInfo PersonInfoProvider
+	enrichNow  chan struct{}
+
+	// exclusiveTracking allows one running session per user, starting work
+	// finishes the running one.
+	exclusiveTracking bool
+}
+
+func NewService(repo *Repository, personInfo PersonInfoProvider, exclusiveTracking bool) *Service {
+	return &Service{
+		repo:              repo,
+		personInfo:        personInfo,
+		enrichNow:         make(chan struct{}, 1),
+		exclusiveTracking: exclusiveTracking,
+	}
+}
+
+// CreateUser stores the user with a pending enrichment, the person info is
+// filled in later by RunEnrichment.
+func (s *Service) CreateUser(ctx context.Context, passportSeries int, passportNumber int) (User, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	user := User{
+		ID:               uuid.Must(uuid.NewV4()),
+		PassportSeries:   passportSeries,
+		PassportNumber:   passportNumber,
+		EnrichmentStatus: EnrichmentPending,
+		CreatedAt:        time.Now(),
+	}
+
+	l.Debug("create user...")
+	err := s.repo.CreateUser(ctx, user)
+	if err != nil {
+		return User{}, fmt.Errorf("create user: %w", err)
+	}
+
+	s.wakeEnrichment()
+
+	user, err = s.repo.UserByID(ctx, user.ID)
+	if err != nil {
+		return User{}, fmt.Errorf("get created user: %w", err)
+	}
+
+	return user, nil
+}
+
+const importChunkSize = 100
+
+// ImportUsers inserts users in chunks, each chunk in its own transaction.
+// A failed row doesn't affect the rest of its chunk.
+func (s *Service) ImportUsers(ctx context.Context, users []ImportUser) []ImportUserResult {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	results := make([]ImportUserResult, 0, len(users))
+
+	for start := 0; start < len(users); start += importChunkSize {
+		chunk := users[start:min(start+importChunkSize, len(users))]
+		chunkResults := make([]ImportUserResult, 0, len(chunk))
+
+		l.Debug("import users chunk...", "from_row", chunk[0].Row, "size", len(chunk))
+		err := s.repo.WithTx(ctx, func(repo *Repository) error {
+			now := time.Now()
+
+			for _, u := range chunk {
+				user := User{
+					ID:               uuid.Must(uuid.NewV4()),
+					PassportSeries:   u.PassportSeries,
+					PassportNumber:   u.PassportNumber,
+					Surname:          u.Surname,
+					Name:             u.Name,
+					Patronymic:       u.Patronymic,
+					Address:          u.Address,
+					EnrichmentStatus: EnrichmentPending,
+					CreatedAt:        now,
+				}
+
+				if u.Surname != "" && u.Name != "" {
+					user.EnrichmentStatus = EnrichmentDone
+				}
+
+				err := repo.WithTx(ctx, func(repo *Repository) error {
+					return repo.CreateUser(ctx, user)
+				})
+				if err != nil {
+					chunkResults = append(chunkResults, ImportUserResult{Row: u.Row, Error: err.Error()})
+					continue
+				}
+
+				chunkResults = append(chunkResults, ImportUserResult{Row: u.Row, ID: &user.ID})
+			}
+
+			return nil
+		})
+		if err != nil {
+			l.Error("import users chunk", "error", err)
+
+			chunkResults = chunkResults[:0]
+			for _, u := range chunk {
+				chunkResults = append(chunkResults, ImportUserResult{Row: u.Row, Error: err.Error()})
+			}
+		}
+
+		results = append(results, chunkResults...)
+	}
+
+	s.wakeEnrichment()
+
+	return results
+}
+
+func (s *Service) wakeEnrichment() {
+	select {
+	case s.enrichNow <- struct{}{}:
+	default:
+	}
+}
+
+// RunEnrichment fills pending users with person info until ctx is done.
+func (s *Service) RunEnrichment(ctx context.Context, cfg EnrichmentConfig) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		err := s.enrichPending(ctx, cfg)
+		if err != nil && ctx.Err() == nil {
+			l.Error("enrich pending users", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.enrichNow:
+		}
+	}
+}
+
+func (s *Service) enrichPending(ctx context.Context, cfg EnrichmentConfig) error {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	for {
+		l.Debug("claim pending enrichments...")
+		enrichments, err := s.repo.ClaimEnrichments(ctx, time.Now(), cfg.Lease, cfg.BatchSize)
+		if err != nil {
+			return fmt.Errorf("claim enrichments: %w", err)
+		}
+
+		for _, e := range enrichments {
+			err = s.enrichUser(ctx, cfg, e)
+			if err != nil {
+				return fmt.Errorf("enrich user %s: %w", e.UserID, err)
+			}
+		}
+
+		if len(enrichments) < cfg.BatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *Service) enrichUser(ctx context.Context, cfg EnrichmentConfig, e Enrichment) error {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger).With("user_id", e.UserID)
+	ctx = context.WithValue(ctx, LoggerCtxKey{}, l)
+
+	l.Debug("get user info...")
+	info, err := s.personInfo.PersonInfo(ctx, e.PassportSeries, e.PassportNumber)
+	if err == nil {
+		err = s.completeEnrichment(ctx, e, info)
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrVersionMismatch) {
+			// the user changed meanwhile, an edit of person info also ended the enrichment
+			// and any other edit leaves the user pending for the next claim
+			l.Info("user changed during enrichment, result discarded")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		l.Info("user enriched")
+		return nil
+	}
+
+	if ctx.Err() != nil {
+		// the lease expires and another run picks the user up
+		return ctx.Err()
+	}
+
+	var nextAt *time.Time
+
+	attempts := e.Attempts + 1
+	if attempts < cfg.MaxAttempts && !errors.Is(err, ErrPersonInfoRejected) {
+		next := time.Now().Add(expDelay(cfg.RetryDelay, cfg.MaxRetryDelay, attempts-1))
+		nextAt = &next
+	}
+
+	l.Warn("enrich user", "error", err, "attempt", attempts, "next_at", nextAt)
+	return s.repo.FailEnrichment(ctx, e.UserID, err.Error(), nextAt)
+}
+
+// ActorEnrichment is the actor of user revisions made by the enrichment worker.
+const ActorEnrichment = "enrichment"
+
+// completeEnrichment saves the person info and records what it changed in user history.
+func (s *Service) completeEnrichment(ctx context.Context, e Enrichment, info PersonInfo) error {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	return s.repo.WithTx(ctx, func(repo *Repository) error {
+		l.Debug("lock user...")
+		old, err := repo.LockUser(ctx, e.UserID)
+		if err != nil {
+			return fmt.Errorf("lock user: %w", err)
+		}
+
+		l.Debug("complete enrichment...")
+		err = repo.CompleteEnrichment(ctx, e, info)
+		if err != nil {
+			return fmt.Errorf("complete enrichment: %w", err)
+		}
+
+		enriched := old
+		enriched.Surname = info.Surname
+		enriched.Name = info.Name
+		enriched.Patronymic = info.Patronymic
+		enriched.Address = info.Address
+
+		changes := diffUsers(old, enriched)
+		if len(changes) == 0 {
+			return nil
+		}
+
+		rev := newUserRevision(ctx, e.UserID, RevisionUpdate, changes)
+		rev.Actor = ActorEnrichment
+
+		l.Debug("create user revision...")
+		return repo.CreateUserRevision(ctx, rev)
+	})
+}
+
+func (s *Service) RetryEnrichment(ctx context.Context, id uuid.UUID) (User, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("retry user enrichment...")
+	err := s.repo.RetryEnrichment(ctx, id, time.Now())
+	if err != nil {
+		return User{}, fmt.Errorf("retry enrichment: %w", err)
+	}
+
+	s.wakeEnrichment()
+
+	l.Debug("get user by ID...")
+	return s.repo.UserByID(ctx, id)
+}
+
+func (s *Service) UserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("get user by ID...")
+	return s.repo.UserByID(ctx, id)
+}
+
+// PersonInfoStatus reports the circuit breaker state of the person info
+// provider, ok is false when the provider has no circuit breaker.
+func (s *Service) PersonInfoStatus() (status CircuitBreakerStatus, ok bool) {
+	p, ok := s.personInfo.(interface{ CircuitBreakerStatus() CircuitBreakerStatus })
+	if !ok {
+		return CircuitBreakerStatus{}, false
+	}
+
+	return p.CircuitBreakerStatus(), true
+}
+
+// UpdateUser applies updUser if the user is still at one of versions, nil
+// versions skips the check.
+func (s *Service) UpdateUser(ctx context.Context, updUser UpdateUser, versions []int) (User, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	var user User
+
+	err := s.repo.WithTx(ctx, func(repo *Repository) error {
+		l.Debug("lock user...")
+		old, err := repo.LockUser(ctx, updUser.ID)
+		if err != nil {
+			return fmt.Errorf("lock user: %w", err)
+		}
+
+		if versions != nil && !slices.Contains(versions, old.Version) {
+			return fmt.Errorf("user is at version %d: %w", old.Version, ErrVersionMismatch)
+		}
+
+		if updUser.PassportSeries != nil || updUser.PassportNumber != nil {
+			series := cmp.Or(updUser.PassportSeries, &old.PassportSeries)
+			number := cmp.Or(updUser.PassportNumber, &old.PassportNumber)
+
+			l.Debug("get user ID by passport...")
+			existingID, err := repo.UserIDByPassport(ctx, *series, *number)
+			if err == nil && existingID != old.ID {
+				return &UserExistsError{ID: existingID}
+			}
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return fmt.Errorf("get user ID by passport: %w", err)
+			}
+		}
+
+		l.Debug("update user...")
+		err = repo.UpdateUser(ctx, updUser)
+		if err != nil {
+			return fmt.Errorf("update user: %w", err)
+		}
+
+		l.Debug("get user by ID...")
+		user, err = repo.UserByID(ctx, updUser.ID)
+		if err != nil {
+			return fmt.Errorf("get user: %w", err)
+		}
+
+		changes := diffUsers(old, user)
+		if len(changes) == 0 {
+			return nil
+		}
+
+		l.Debug("create user revision...")
+		return repo.CreateUserRevision(ctx, newUserRevision(ctx, user.ID, RevisionUpdate, changes))
+	})
+	if err != nil {
+		return User{}, err
+	}
+
+	return user, nil
+}
+
+func diffUsers(before, after User) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+
+	add := func(field string, o, n any) {
+		if o != n {
+			changes[field] = FieldChange{Old: o, New: n}
+		}
+	}
+
+	add("passport_series", before.PassportSeries, after.PassportSeries)
+	add("passport_number", before.PassportNumber, after.PassportNumber)
+	add("surname", before.Surname, after.Surname)
+	add("name", before.Name, after.Name)
+	add("patronymic", before.Patronymic, after.Patronymic)
+	add("address", before.Address, after.Address)
+	add("time_zone", before.TimeZone, after.TimeZone)
+
+	return changes
+}
+
+func newUserRevision(ctx context.Context, userID uuid.UUID, action string, changes map[string]FieldChange) UserRevision {
+	return UserRevision{
+		ID:        uuid.Must(uuid.NewV4()),
+		UserID:    userID,
+		Action:    action,
+		Changes:   changes,
+		Actor:     actor(ctx),
+		CreatedAt: time.Now(),
+	}
 }
 
-func NewService(repo *Repository, apiURL string) *Service {
-	client := &http.Client{
-		Timeout: time.Second * 5,
+func (s *Service) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	return s.repo.WithTx(ctx, func(repo *Repository) error {
+		deletedAt := time.Now()
+
+		l.Debug("delete user...")
+		err := repo.DeleteUser(ctx, id, deletedAt)
+		if err != nil {
+			return err
+		}
+
+		changes := map[string]FieldChange{
+			"deleted_at": {Old: nil, New: deletedAt},
+		}
+
+		l.Debug("create user revision...")
+		return repo.CreateUserRevision(ctx, newUserRevision(ctx, id, RevisionDelete, changes))
+	})
+}
+
+func (s *Service) RestoreUser(ctx context.Context, id uuid.UUID) (User, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	var user User
+
+	err := s.repo.WithTx(ctx, func(repo *Repository) error {
+		l.Debug("restore user...")
+		deletedAt, err := repo.RestoreUser(ctx, id)
+		if err != nil {
+			return fmt.Errorf("restore user: %w", err)
+		}
+
+		changes := map[string]FieldChange{
+			"deleted_at": {Old: deletedAt, New: nil},
+		}
+
+		l.Debug("create user revision...")
+		err = repo.CreateUserRevision(ctx, newUserRevision(ctx, id, RevisionRestore, changes))
+		if err != nil {
+			return fmt.Errorf("create user revision: %w", err)
+		}
+
+		l.Debug("get user by ID...")
+		user, err = repo.UserByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return User{}, err
+	}
+
+	return user, nil
+}
+
+func (s *Service) UserRevisions(ctx context.Context, id uuid.UUID) ([]UserRevision, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("get user revisions...")
+	return s.repo.UserRevisions(ctx, id)
+}
+
+func (s *Service) PurgeUser(ctx context.Context, id uuid.UUID) error {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Info("purge user...", "user_id", id, "actor", actor(ctx))
+	return s.repo.WithTx(ctx, func(repo *Repository) error {
+		return repo.PurgeUser(ctx, id)
+	})
+}
+
+func (s *Service) StartWork(ctx context.Context, userID, taskID uuid.UUID) (WorkHours, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	if s.exclusiveTracking {
+		sw, err := s.SwitchWork(ctx, userID, taskID)
+		if err != nil {
+			return WorkHours{}, err
+		}
+		return sw.Started, nil
+	}
+
+	wh := WorkHours{
+		ID:     uuid.Must(uuid.NewV4()),
+		UserID: userID,
+		TaskID: taskID,
+	}
+
+	err := s.repo.WithTx(ctx, func(repo *Repository) error {
+		l.Debug("get user by ID...")
+		_, err := repo.UserByID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("get user: %w", err)
+		}
+
+		// the task can't be deleted until the session is started
+		l.Debug("share lock task...")
+		task, err := repo.ShareLockTask(ctx, taskID)
+		if err != nil {
+			return fmt.Errorf("lock task: %w", err)
+		}
+
+		if task.ClosedAt != nil {
+			return ErrTaskClosed
+		}
+
+		wh.StartedAt = time.Now()
+
+		l.Debug("start work...")
+		return repo.StartWork(ctx, wh)
+	})
+	if err != nil {
+		return WorkHours{}, err
+	}
+
+	return wh, nil
+}
+
+// FinishWork closes the running session, a paused session is resumed first so
+// spend_time_sec counts only the active intervals.
+func (s *Service) FinishWork(ctx context.Context, userID, taskID uuid.UUID) (WorkHours, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	var wh WorkHours
+
+	err := s.repo.WithTx(ctx, func(repo *Repository) error {
+		var err error
+
+		wh, err = repo.LockNotFinishedWorkHours(ctx, userID, taskID)
+		if err != nil {
+			return err
+		}
+
+		l.Debug("finish work...")
+		wh, err = finishWork(ctx, repo, wh, time.Now())
+		return err
+	})
+	if err != nil {
+		return WorkHours{}, err
+	}
+
+	return wh, nil
+}
+
+// finishWork closes a locked running session at now.
+func finishWork(ctx context.Context, repo *Repository, wh WorkHours, now time.Time) (WorkHours, error) {
+	err := repo.ResumeWork(ctx, wh.ID, now)
+	if err != nil && !errors.Is(err, ErrWorkNotPaused) {
+		return WorkHours{}, fmt.Errorf("resume work: %w", err)
+	}
+
+	pausedSec, err := repo.PausedSec(ctx, wh.ID, wh.StartedAt, now)
+	if err != nil {
+		return WorkHours{}, fmt.Errorf("get paused time: %w", err)
+	}
+
+	wh.FinishedAt = &now
+	wh.SpendTimeSec = max(int(wh.FinishedAt.Sub(wh.StartedAt).Seconds())-pausedSec, 0)
+
+	err = repo.FinishWork(ctx, wh)
+	if err != nil {
+		return WorkHours{}, err
+	}
+
+	return wh, nil
+}
+
+// RunReaper finishes sessions that run longer than cfg.MaxOpen until ctx is done.
+func (s *Service) RunReaper(ctx context.Context, cfg ReaperConfig) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		err := s.reapOverdueWork(ctx, cfg)
+		if err != nil && ctx.Err() == nil {
+			l.Error("reap overdue work", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) reapOverdueWork(ctx context.Context, cfg ReaperConfig) error {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	for {
+		var reaped int
+
+		err := s.repo.WithTx(ctx, func(repo *Repository) error {
+			l.Debug("claim overdue work...")
+			overdue, err := repo.ClaimOverdueWorkHours(ctx, time.Now().Add(-cfg.MaxOpen), cfg.BatchSize)
+			if err != nil {
+				return fmt.Errorf("claim overdue work: %w", err)
+			}
+
+			for _, wh := range overdue {
+				wh.AutoClosed = true
+
+				closed, err := finishWork(ctx, repo, wh, wh.StartedAt.Add(cfg.MaxOpen))
+				if err != nil {
+					return fmt.Errorf("finish work %s: %w", wh.ID, err)
+				}
+
+				l.Info("work auto-closed", "work_hours_id", closed.ID, "user_id", closed.UserID, "finished_at", closed.FinishedAt)
+			}
+
+			reaped = len(overdue)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if reaped == 0 || reaped < cfg.BatchSize {
+			return nil
+		}
+	}
+}
+
+// SwitchWork finishes every running session of the user and starts work on
+// the task in one transaction.
+func (s *Service) SwitchWork(ctx context.Context, userID, taskID uuid.UUID) (SwitchWork, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	sw := SwitchWork{Finished: []WorkHours{}}
+
+	err := s.repo.WithTx(ctx, func(repo *Repository) error {
+		// starts of one user wait for each other, so only one session stays open
+		l.Debug("lock user...")
+		_, err := repo.LockUser(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("lock user: %w", err)
+		}
+
+		// the task can't be deleted until the session is started
+		l.Debug("share lock task...")
+		task, err := repo.ShareLockTask(ctx, taskID)
+		if err != nil {
+			return fmt.Errorf("lock task: %w", err)
+		}
+
+		if task.ClosedAt != nil {
+			return ErrTaskClosed
+		}
+
+		l.Debug("lock running work...")
+		running, err := repo.LockUserNotFinishedWorkHours(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("lock running work: %w", err)
+		}
+
+		for _, wh := range running {
+			if wh.TaskID == taskID {
+				return ErrWorkAlreadyStarted
+			}
+		}
+
+		now := time.Now()
+
+		for _, wh := range running {
+			l.Debug("finish work...")
+			wh, err = finishWork(ctx, repo, wh, now)
+			if err != nil {
+				return err
+			}
+
+			sw.Finished = append(sw.Finished, wh)
+		}
+
+		sw.Started = WorkHours{
+			ID:        uuid.Must(uuid.NewV4()),
+			UserID:    userID,
+			TaskID:    taskID,
+			StartedAt: now,
+		}
+
+		l.Debug("start work...")
+		return repo.StartWork(ctx, sw.Started)
+	})
+	if err != nil {
+		return SwitchWork{}, err
+	}
+
+	return sw, nil
+}
+
+func (s *Service) PauseWork(ctx context.Context, userID, taskID uuid.UUID) error {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	return s.repo.WithTx(ctx, func(repo *Repository) error {
+		wh, err := repo.LockNotFinishedWorkHours(ctx, userID, taskID)
+		if err != nil {
+			return fmt.Errorf("get running work: %w", err)
+		}
+
+		p := WorkPause{
+			ID:          uuid.Must(uuid.NewV4()),
+			WorkHoursID: wh.ID,
+			PausedAt:    time.Now(),
+		}
+
+		l.Debug("pause work...")
+		return repo.PauseWork(ctx, p)
+	})
+}
+
+func (s *Service) ResumeWork(ctx context.Context, userID, taskID uuid.UUID) error {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	return s.repo.WithTx(ctx, func(repo *Repository) error {
+		wh, err := repo.LockNotFinishedWorkHours(ctx, userID, taskID)
+		if err != nil {
+			return fmt.Errorf("get running work: %w", err)
+		}
+
+		l.Debug("resume work...")
+		return repo.ResumeWork(ctx, wh.ID, time.Now())
+	})
+}
+
+func (s *Service) WorkEntryByID(ctx context.Context, id uuid.UUID) (WorkHours, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("get work entry by ID...")
+	return s.repo.WorkHoursByID(ctx, id)
+}
+
+func (s *Service) WorkEntries(ctx context.Context, page WorkEntryPage, filter WorkEntryFilter) (WorkEntriesPage, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("get work entries...")
+	entries, err := s.repo.WorkEntries(ctx, page, filter)
+	if err != nil {
+		return WorkEntriesPage{}, fmt.Errorf("get work entries: %w", err)
 	}
 
-	return &Service{
-		repo:   repo,
-		client: client,
-		apiURL: apiURL,
+	l.Debug("count work entries...")
+	total, err := s.repo.CountWorkEntries(ctx, filter)
+	if err != nil {
+		return WorkEntriesPage{}, fmt.Errorf("count work entries: %w", err)
 	}
+
+	return WorkEntriesPage{Entries: entries, Total: total}, nil
 }
 
-func (s *Service) CreateUser(ctx context.Context, passportSeries int, passportNumber int) error {
+// CreateWorkEntry logs a finished past interval of work.
+func (s *Service) CreateWorkEntry(ctx context.Context, entry CreateWorkEntry) (WorkHours, error) {
 	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
 
-	l.Debug("get user info...")
-	user, err := s.getUserInfo(ctx, passportSeries, passportNumber)
+	wh := WorkHours{
+		ID:         uuid.Must(uuid.NewV4()),
+		UserID:     entry.UserID,
+		TaskID:     entry.TaskID,
+		StartedAt:  entry.StartedAt,
+		FinishedAt: &entry.FinishedAt,
+	}
+
+	err := validateWorkEntry(wh, time.Now())
 	if err != nil {
-		return fmt.Errorf("get user info: %w", err)
+		return WorkHours{}, err
 	}
 
-	user.ID = uuid.Must(uuid.NewV4())
-	user.PassportSeries = passportSeries
-	user.PassportNumber = passportNumber
-	user.CreatedAt = time.Now()
+	wh.SpendTimeSec = int(entry.FinishedAt.Sub(entry.StartedAt).Seconds())
 
-	l.Debug("create user...")
-	err = s.repo.CreateUser(ctx, user)
+	err = s.repo.WithTx(ctx, func(repo *Repository) error {
+		// entries of one user are checked for overlaps one at a time
+		l.Debug("lock user...")
+		_, err := repo.LockUser(ctx, wh.UserID)
+		if err != nil {
+			return fmt.Errorf("lock user: %w", err)
+		}
+
+		err = checkWorkEntryTask(ctx, repo, wh.TaskID)
+		if err != nil {
+			return err
+		}
+
+		err = checkWorkEntryOverlap(ctx, repo, wh)
+		if err != nil {
+			return err
+		}
+
+		l.Debug("create work entry...")
+		return repo.CreateWorkHours(ctx, wh)
+	})
 	if err != nil {
-		return fmt.Errorf("create user: %w", err)
+		return WorkHours{}, err
 	}
 
-	return nil
+	return wh, nil
 }
 
-func (s *Service) getUserInfo(ctx context.Context, passportSeries, passportNumber int) (User, error) {
-	url := fmt.Sprintf("%s/info?passportSerie=%d&passportNumber=%d", s.apiURL, passportSeries, passportNumber)
+// UpdateWorkEntry edits a finished entry and recomputes its spend time,
+// running sessions are changed by pause, resume and finish only.
+func (s *Service) UpdateWorkEntry(ctx context.Context, id uuid.UUID, updEntry UpdateWorkEntry) (WorkHours, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	var wh WorkHours
+
+	err := s.repo.WithTx(ctx, func(repo *Repository) error {
+		l.Debug("lock work entry...")
+		old, err := repo.LockWorkHours(ctx, id)
+		if err != nil {
+			return fmt.Errorf("lock work entry: %w", err)
+		}
+
+		if old.FinishedAt == nil {
+			return ErrWorkNotFinished
+		}
+
+		l.Debug("lock user...")
+		_, err = repo.LockUser(ctx, old.UserID)
+		if err != nil {
+			return fmt.Errorf("lock user: %w", err)
+		}
+
+		wh = old
+		if updEntry.TaskID != nil {
+			wh.TaskID = *updEntry.TaskID
+		}
+		if updEntry.StartedAt != nil {
+			wh.StartedAt = *updEntry.StartedAt
+		}
+		if updEntry.FinishedAt != nil {
+			wh.FinishedAt = updEntry.FinishedAt
+			// a corrected finish is the review of an auto-closed session
+			wh.AutoClosed = false
+		}
+
+		err = validateWorkEntry(wh, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if wh.TaskID != old.TaskID {
+			err = checkWorkEntryTask(ctx, repo, wh.TaskID)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = checkWorkEntryOverlap(ctx, repo, wh)
+		if err != nil {
+			return err
+		}
+
+		pausedSec, err := repo.PausedSec(ctx, wh.ID, wh.StartedAt, *wh.FinishedAt)
+		if err != nil {
+			return fmt.Errorf("get paused time: %w", err)
+		}
+
+		wh.SpendTimeSec = max(int(wh.FinishedAt.Sub(wh.StartedAt).Seconds())-pausedSec, 0)
 
-	var req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		l.Debug("update work entry...")
+		return repo.UpdateWorkHours(ctx, wh)
+	})
 	if err != nil {
-		return User{}, fmt.Errorf("create request: %w", err)
+		return WorkHours{}, err
+	}
+
+	return wh, nil
+}
+
+// DeleteWorkEntry deletes an entry together with its pauses.
+func (s *Service) DeleteWorkEntry(ctx context.Context, id uuid.UUID) error {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	return s.repo.WithTx(ctx, func(repo *Repository) error {
+		l.Debug("lock work entry...")
+		_, err := repo.LockWorkHours(ctx, id)
+		if err != nil {
+			return fmt.Errorf("lock work entry: %w", err)
+		}
+
+		l.Debug("delete work pauses...")
+		err = repo.DeleteWorkPauses(ctx, id)
+		if err != nil {
+			return fmt.Errorf("delete work pauses: %w", err)
+		}
+
+		l.Debug("delete work entry...")
+		return repo.DeleteWorkHours(ctx, id)
+	})
+}
+
+func validateWorkEntry(wh WorkHours, now time.Time) error {
+	if !wh.FinishedAt.After(wh.StartedAt) {
+		return fmt.Errorf("%w: finished_at must be after started_at", ErrInvalidWorkEntry)
 	}
 
-	resp, err := s.client.Do(req)
+	if wh.FinishedAt.After(now) {
+		return fmt.Errorf("%w: finished_at must not be in the future", ErrInvalidWorkEntry)
+	}
+
+	return nil
+}
+
+func checkWorkEntryTask(ctx context.Context, repo *Repository, taskID uuid.UUID) error {
+	task, err := repo.TaskByID(ctx, taskID)
 	if err != nil {
-		return User{}, fmt.Errorf("send request: %w", err)
+		return fmt.Errorf("get task: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return User{}, fmt.Errorf("unexpected response code: %d", resp.StatusCode)
+	if task.ClosedAt != nil {
+		return ErrTaskClosed
 	}
 
-	var user User
+	return nil
+}
 
-	err = json.NewDecoder(resp.Body).Decode(&user)
+func checkWorkEntryOverlap(ctx context.Context, repo *Repository, wh WorkHours) error {
+	overlap, err := repo.WorkHoursOverlap(ctx, wh.UserID, wh.StartedAt, *wh.FinishedAt, wh.ID)
 	if err != nil {
-		return User{}, fmt.Errorf("parse body: %w", err)
+		return fmt.Errorf("check overlap: %w", err)
 	}
 
-	return user, nil
+	if overlap {
+		return ErrWorkOverlap
+	}
+
+	return nil
+}
+
+func (s *Service) ActiveWork(ctx context.Context) ([]ActiveWork, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("get active work...")
+	return s.repo.ActiveWork(ctx, nil, time.Now())
 }
 
-func (s *Service) UpdateUser(ctx context.Context, updUser UpdateUser) (User, error) {
+func (s *Service) UserActiveWork(ctx context.Context, userID uuid.UUID) ([]ActiveWork, error) {
 	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
 
-	l.Debug("update user...")
-	err := s.repo.UpdateUser(ctx, updUser)
+	l.Debug("get user by ID...")
+	_, err := s.repo.UserByID(ctx, userID)
 	if err != nil {
-		return User{}, fmt.Errorf("update user: %w", err)
+		return nil, fmt.Errorf("get user: %w", err)
 	}
 
-	l.Debug("get user by ID...")
-	return s.repo.UserByID(ctx, updUser.ID)
+	l.Debug("get active work...")
+	return s.repo.ActiveWork(ctx, &userID, time.Now())
 }
 
-func (s *Service) DeleteUser(ctx context.Context, id uuid.UUID) error {
+// maxReportBuckets limits the length of a time series report.
+const maxReportBuckets = 1000
+
+// TaskSpendTimeBuckets splits the period into days, weeks starting on
+// weekStart or months in period.Location and reports task spend times of each.
+func (s *Service) TaskSpendTimeBuckets(ctx context.Context, id uuid.UUID, period Period, groupBy string, weekStart time.Weekday) ([]ReportBucket, error) {
 	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
 
-	l.Debug("delete user...")
-	return s.repo.DeleteUser(ctx, id, time.Now())
+	if period.StartDate.IsZero() {
+		return nil, fmt.Errorf("%w: start date is required to group by %s", ErrInvalidPeriod, groupBy)
+	}
+
+	var buckets []ReportBucket
+	var starts, ends []time.Time
+
+	next := nextBucket(groupBy)
+
+	for b := bucketStart(period.StartDate.In(period.Location), groupBy, weekStart); b.Before(period.EndDate); b = next(b) {
+		if len(buckets) == maxReportBuckets {
+			return nil, fmt.Errorf("%w: more than %d buckets", ErrInvalidPeriod, maxReportBuckets)
+		}
+
+		bucket := ReportBucket{
+			Start: maxTime(b, period.StartDate).In(period.Location),
+			End:   minTime(next(b), period.EndDate).In(period.Location),
+		}
+
+		buckets = append(buckets, bucket)
+		starts = append(starts, bucket.Start)
+		ends = append(ends, bucket.End)
+	}
+
+	l.Debug("get task spend times by buckets...")
+	spendTimes, err := s.repo.TaskSpendTimesByBuckets(ctx, id, starts, ends, period.RunningUntil)
+	if err != nil {
+		return nil, fmt.Errorf("get task spend times by buckets: %w", err)
+	}
+
+	for i := range buckets {
+		buckets[i].Tasks = spendTimes[i]
+		if buckets[i].Tasks == nil {
+			buckets[i].Tasks = []TaskSpendTime{}
+		}
+
+		for _, t := range buckets[i].Tasks {
+			buckets[i].SpendTimeSec += t.SpendTimeSec
+		}
+	}
+
+	return buckets, nil
 }
 
-func (s *Service) StartWork(ctx context.Context, userID, taskID uuid.UUID) error {
-	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+// bucketStart is the midnight the day, week or month of t starts at in the location of t.
+func bucketStart(t time.Time, groupBy string, weekStart time.Weekday) time.Time {
+	y, m, d := t.Date()
 
-	_, err := s.repo.NotFinishedWorkHours(ctx, userID, taskID)
-	if err == nil {
-		return ErrWorkAlreadyStarted
+	switch groupBy {
+	case GroupByWeek:
+		d -= int((7 + t.Weekday() - weekStart) % 7)
+	case GroupByMonth:
+		d = 1
 	}
 
-	if !errors.Is(err, ErrNotFound) {
-		return err
+	return dayStart(y, m, d, t.Location())
+}
+
+// nextBucket moves the start of a bucket to the start of the next one. The
+// date is moved rather than the time, a day starting at 01:00 because DST
+// skipped its midnight is followed by one starting at midnight again.
+func nextBucket(groupBy string) func(time.Time) time.Time {
+	return func(t time.Time) time.Time {
+		y, m, d := t.Date()
+
+		switch groupBy {
+		case GroupByWeek:
+			d += 7
+		case GroupByMonth:
+			m++
+		default:
+			d++
+		}
+
+		return dayStart(y, m, d, t.Location())
 	}
+}
 
-	wh := WorkHours{
-		UserID:    userID,
-		TaskID:    taskID,
-		StartedAt: time.Now(),
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
 	}
+	return b
+}
 
-	l.Debug("start work...")
-	return s.repo.StartWork(ctx, wh)
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
 }
 
-func (s *Service) FinishWork(ctx context.Context, userID, taskID uuid.UUID) error {
+// ReportPeriod turns inclusive dates into a period from the start of startDate
+// to the end of endDate in loc, or in the time zone of the user when loc is nil.
+// Only the year, month and day of the dates are used, a zero startDate leaves
+// the period open and a zero endDate means today. With includeRunning running
+// sessions count up to now.
+func (s *Service) ReportPeriod(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, loc *time.Location, includeRunning bool) (Period, error) {
 	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
 
-	wh, err := s.repo.NotFinishedWorkHours(ctx, userID, taskID)
+	l.Debug("get user by ID...")
+	user, err := s.repo.UserByID(ctx, userID)
 	if err != nil {
-		return err
+		return Period{}, fmt.Errorf("get user: %w", err)
+	}
+
+	if loc == nil {
+		loc, err = time.LoadLocation(user.TimeZone)
+		if err != nil {
+			return Period{}, fmt.Errorf("load user time zone: %w", err)
+		}
 	}
 
 	now := time.Now()
-	wh.FinishedAt = &now
-	wh.SpendTimeSec = int(wh.FinishedAt.Sub(wh.StartedAt).Seconds())
 
-	l.Debug("finish work...")
-	err = s.repo.FinishWork(ctx, wh)
-	if err != nil {
-		return err
+	if endDate.IsZero() {
+		endDate = now.In(loc)
 	}
 
-	return nil
+	y, m, d := endDate.Date()
+
+	period := Period{
+		EndDate:  dayStart(y, m, d+1, loc),
+		Location: loc,
+	}
+
+	if !startDate.IsZero() {
+		period.StartDate = startOfDay(startDate, loc)
+	}
+
+	if includeRunning {
+		period.RunningUntil = &now
+	}
+
+	if !period.StartDate.Before(period.EndDate) {
+		return Period{}, fmt.Errorf("%w: start date is after end date", ErrInvalidPeriod)
+	}
+
+	return period, nil
+}
+
+// startOfDay is the start of the date of t in loc.
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.Date()
+	return dayStart(y, m, d, loc)
+}
+
+// dayStart is midnight of the date in loc, the date is normalized like in
+// time.Date. When DST skips midnight the day starts where the gap ends, while
+// time.Date may resolve such a midnight to the last hour of the previous day.
+func dayStart(y int, m time.Month, d int, loc *time.Location) time.Time {
+	t := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	if t.Hour() > 12 {
+		_, t = t.ZoneBounds()
+	}
+	return t
 }
 
 func (s *Service) TaskSpendTimesByUser(ctx context.Context, id uuid.UUID, period Period) ([]TaskSpendTime, error) {
@@ -163,9 +1140,279 @@ func (s *Service) TaskSpendTimesByUser(ctx context.Context, id uuid.UUID, period
 	return spendTimesByUser, nil
 }
 
-func (s *Service) Users(ctx context.Context, page, perPage int, filter UserFilter) ([]User, error) {
+func (s *Service) Users(ctx context.Context, page UserPage, filter UserFilter) (UsersPage, error) {
 	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
 
 	l.Debug("get users...")
-	return s.repo.Users(ctx, page, perPage, filter)
+	if len(page.Sort) == 0 {
+		page.Sort = []UserSort{{Field: SortByCreatedAt, Desc: true}}
+	}
+
+	users, err := s.repo.Users(ctx, page, filter)
+	if err != nil {
+		return UsersPage{}, fmt.Errorf("get users: %w", err)
+	}
+
+	l.Debug("count users...")
+	total, err := s.repo.CountUsers(ctx, filter)
+	if err != nil {
+		return UsersPage{}, fmt.Errorf("count users: %w", err)
+	}
+
+	res := UsersPage{
+		Users: users,
+		Total: total,
+	}
+
+	if len(users) > page.PerPage {
+		res.Users = users[:page.PerPage]
+
+		last := res.Users[len(res.Users)-1]
+		cursor := newUserCursor(last, page.Sort)
+		res.NextCursor = &cursor
+	}
+
+	return res, nil
+}
+
+func (s *Service) CreateTask(ctx context.Context, createTask CreateTask) (Task, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("get project by ID...")
+	_, err := s.repo.ProjectByID(ctx, createTask.ProjectID)
+	if err != nil {
+		return Task{}, fmt.Errorf("get project: %w", err)
+	}
+
+	task := Task{
+		ID:          uuid.Must(uuid.NewV4()),
+		ProjectID:   createTask.ProjectID,
+		Title:       createTask.Title,
+		Description: createTask.Description,
+		CreatedAt:   time.Now(),
+	}
+
+	l.Debug("create task...")
+	err = s.repo.CreateTask(ctx, task)
+	if err != nil {
+		return Task{}, fmt.Errorf("create task: %w", err)
+	}
+
+	return task, nil
+}
+
+func (s *Service) TaskByID(ctx context.Context, id uuid.UUID) (Task, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("get task by ID...")
+	return s.repo.TaskByID(ctx, id)
+}
+
+func (s *Service) Tasks(ctx context.Context, filter TaskFilter) ([]Task, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("get tasks...")
+	return s.repo.Tasks(ctx, filter)
+}
+
+func (s *Service) UpdateTask(ctx context.Context, id uuid.UUID, updTask UpdateTask) (Task, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	if updTask.ProjectID != nil {
+		l.Debug("get project by ID...")
+		_, err := s.repo.ProjectByID(ctx, *updTask.ProjectID)
+		if err != nil {
+			return Task{}, fmt.Errorf("get project: %w", err)
+		}
+	}
+
+	l.Debug("update task...")
+	err := s.repo.UpdateTask(ctx, id, updTask, time.Now())
+	if err != nil {
+		return Task{}, fmt.Errorf("update task: %w", err)
+	}
+
+	l.Debug("get task by ID...")
+	return s.repo.TaskByID(ctx, id)
+}
+
+func (s *Service) DeleteTask(ctx context.Context, id uuid.UUID) error {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	return s.repo.WithTx(ctx, func(repo *Repository) error {
+		// starts share-lock the task, so none is in flight while it's checked and deleted
+		l.Debug("lock task...")
+		_, err := repo.LockTask(ctx, id)
+		if err != nil {
+			return fmt.Errorf("lock task: %w", err)
+		}
+
+		open := true
+
+		l.Debug("count running sessions of the task...")
+		running, err := repo.CountWorkEntries(ctx, WorkEntryFilter{TaskID: &id, Open: &open})
+		if err != nil {
+			return fmt.Errorf("count running sessions: %w", err)
+		}
+
+		if running > 0 {
+			return fmt.Errorf("task has %d running sessions: %w", running, ErrInUse)
+		}
+
+		l.Debug("delete task...")
+		return repo.DeleteTask(ctx, id, time.Now())
+	})
+}
+
+func (s *Service) ProjectSpendTimesByUser(ctx context.Context, id uuid.UUID, period Period) ([]ProjectSpendTime, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("get project spend times by user...")
+	return s.repo.ProjectSpendTimesByUser(ctx, id, period)
+}
+
+func (s *Service) ClientSpendTimesByUser(ctx context.Context, id uuid.UUID, period Period) ([]ClientSpendTime, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("get client spend times by user...")
+	return s.repo.ClientSpendTimesByUser(ctx, id, period)
+}
+
+func (s *Service) CreateClient(ctx context.Context, createClient CreateClient) (Client, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	client := Client{
+		ID:        uuid.Must(uuid.NewV4()),
+		Name:      createClient.Name,
+		CreatedAt: time.Now(),
+	}
+
+	l.Debug("create client...")
+	err := s.repo.CreateClient(ctx, client)
+	if err != nil {
+		return Client{}, fmt.Errorf("create client: %w", err)
+	}
+
+	return client, nil
+}
+
+func (s *Service) ClientByID(ctx context.Context, id uuid.UUID) (Client, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("get client by ID...")
+	return s.repo.ClientByID(ctx, id)
+}
+
+func (s *Service) Clients(ctx context.Context) ([]Client, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("get clients...")
+	return s.repo.Clients(ctx)
+}
+
+func (s *Service) UpdateClient(ctx context.Context, id uuid.UUID, updClient UpdateClient) (Client, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("update client...")
+	err := s.repo.UpdateClient(ctx, id, updClient)
+	if err != nil {
+		return Client{}, fmt.Errorf("update client: %w", err)
+	}
+
+	l.Debug("get client by ID...")
+	return s.repo.ClientByID(ctx, id)
+}
+
+func (s *Service) DeleteClient(ctx context.Context, id uuid.UUID) error {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("get client projects...")
+	projects, err := s.repo.Projects(ctx, ProjectFilter{ClientID: &id})
+	if err != nil {
+		return fmt.Errorf("get client projects: %w", err)
+	}
+
+	if len(projects) > 0 {
+		return fmt.Errorf("client has %d projects: %w", len(projects), ErrInUse)
+	}
+
+	l.Debug("delete client...")
+	return s.repo.DeleteClient(ctx, id, time.Now())
+}
+
+func (s *Service) CreateProject(ctx context.Context, createProject CreateProject) (Project, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("get client by ID...")
+	_, err := s.repo.ClientByID(ctx, createProject.ClientID)
+	if err != nil {
+		return Project{}, fmt.Errorf("get client: %w", err)
+	}
+
+	project := Project{
+		ID:        uuid.Must(uuid.NewV4()),
+		ClientID:  createProject.ClientID,
+		Name:      createProject.Name,
+		CreatedAt: time.Now(),
+	}
+
+	l.Debug("create project...")
+	err = s.repo.CreateProject(ctx, project)
+	if err != nil {
+		return Project{}, fmt.Errorf("create project: %w", err)
+	}
+
+	return project, nil
+}
+
+func (s *Service) ProjectByID(ctx context.Context, id uuid.UUID) (Project, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("get project by ID...")
+	return s.repo.ProjectByID(ctx, id)
+}
+
+func (s *Service) Projects(ctx context.Context, filter ProjectFilter) ([]Project, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("get projects...")
+	return s.repo.Projects(ctx, filter)
+}
+
+func (s *Service) UpdateProject(ctx context.Context, id uuid.UUID, updProject UpdateProject) (Project, error) {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	if updProject.ClientID != nil {
+		l.Debug("get client by ID...")
+		_, err := s.repo.ClientByID(ctx, *updProject.ClientID)
+		if err != nil {
+			return Project{}, fmt.Errorf("get client: %w", err)
+		}
+	}
+
+	l.Debug("update project...")
+	err := s.repo.UpdateProject(ctx, id, updProject)
+	if err != nil {
+		return Project{}, fmt.Errorf("update project: %w", err)
+	}
+
+	l.Debug("get project by ID...")
+	return s.repo.ProjectByID(ctx, id)
+}
+
+func (s *Service) DeleteProject(ctx context.Context, id uuid.UUID) error {
+	l := ctx.Value(LoggerCtxKey{}).(*slog.Logger)
+
+	l.Debug("get project tasks...")
+	tasks, err := s.repo.Tasks(ctx, TaskFilter{ProjectID: &id})
+	if err != nil {
+		return fmt.Errorf("get project tasks: %w", err)
+	}
+
+	if len(tasks) > 0 {
+		return fmt.Errorf("project has %d tasks: %w", len(tasks), ErrInUse)
+	}
+
+	l.Debug("delete project...")
+	return s.repo.DeleteProject(ctx, id, time.Now())
 }
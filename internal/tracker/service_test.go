@@ -0,0 +1,420 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type personInfoFunc func(ctx context.Context, passportSeries, passportNumber int) (PersonInfo, error)
+
+func (f personInfoFunc) PersonInfo(ctx context.Context, passportSeries, passportNumber int) (PersonInfo, error) {
+	return f(ctx, passportSeries, passportNumber)
+}
+
+func TestEnrichUserRecordsRevision(t *testing.T) {
+	db := newFakeDB(t)
+	info := PersonInfo{Surname: "Ivanov", Name: "Ivan", Patronymic: "Ivanovich", Address: "Moscow"}
+	s, ctx := newTestService(t, db, personInfoFunc(func(context.Context, int, int) (PersonInfo, error) {
+		return info, nil
+	}))
+
+	user := User{ID: uuid.Must(uuid.NewV4()), PassportSeries: 1234, PassportNumber: 567890, EnrichmentStatus: EnrichmentPending, Version: 1}
+	db.users[user.ID] = user
+
+	err := s.enrichUser(ctx, EnrichmentConfig{MaxAttempts: 3}, Enrichment{UserID: user.ID, PassportSeries: 1234, PassportNumber: 567890, Version: 1})
+	if err != nil {
+		t.Fatalf("enrich user: %v", err)
+	}
+
+	got := db.users[user.ID]
+	if got.Version != 2 || got.EnrichmentStatus != EnrichmentDone {
+		t.Errorf("user version %d, status %q, want 2, %q", got.Version, got.EnrichmentStatus, EnrichmentDone)
+	}
+
+	if len(db.revisions) != 1 {
+		t.Fatalf("got %d revisions, want 1", len(db.revisions))
+	}
+
+	rev := db.revisions[0]
+	if rev.Actor != ActorEnrichment || rev.Action != RevisionUpdate || rev.UserID != user.ID {
+		t.Errorf("revision actor %q, action %q, user %s", rev.Actor, rev.Action, rev.UserID)
+	}
+
+	want := map[string]FieldChange{
+		"surname":    {Old: "", New: "Ivanov"},
+		"name":       {Old: "", New: "Ivan"},
+		"patronymic": {Old: "", New: "Ivanovich"},
+		"address":    {Old: "", New: "Moscow"},
+	}
+	if len(rev.Changes) != len(want) {
+		t.Errorf("revision changes = %v, want %v", rev.Changes, want)
+	}
+	for field, change := range want {
+		if rev.Changes[field] != change {
+			t.Errorf("revision change of %s = %v, want %v", field, rev.Changes[field], change)
+		}
+	}
+}
+
+func TestEnrichUserDiscardsStaleResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(u *User)
+	}{
+		{"edited after the claim", func(u *User) { u.Surname = "Petrov"; u.Version++ }},
+		{"deleted after the claim", func(u *User) { now := time.Now(); u.DeletedAt = &now; u.Version++ }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB(t)
+			s, ctx := newTestService(t, db, personInfoFunc(func(context.Context, int, int) (PersonInfo, error) {
+				return PersonInfo{Surname: "Ivanov", Name: "Ivan"}, nil
+			}))
+
+			user := User{ID: uuid.Must(uuid.NewV4()), EnrichmentStatus: EnrichmentPending, Version: 1}
+			e := Enrichment{UserID: user.ID, Version: user.Version}
+
+			tt.change(&user)
+			db.users[user.ID] = user
+
+			err := s.enrichUser(ctx, EnrichmentConfig{MaxAttempts: 3}, e)
+			if err != nil {
+				t.Fatalf("enrich user: %v", err)
+			}
+
+			if got := db.users[user.ID]; got != user {
+				t.Errorf("user = %+v, want unchanged %+v", got, user)
+			}
+			if len(db.revisions) != 0 {
+				t.Errorf("got revisions %v, want none", db.revisions)
+			}
+		})
+	}
+}
+
+func TestUpdateUserEndsPendingEnrichment(t *testing.T) {
+	db := newFakeDB(t)
+
+	var calls int
+	s, ctx := newTestService(t, db, personInfoFunc(func(context.Context, int, int) (PersonInfo, error) {
+		calls++
+		return PersonInfo{Surname: "Ivanov", Name: "Ivan", Patronymic: "Ivanovich", Address: "Moscow"}, nil
+	}))
+
+	user := User{ID: uuid.Must(uuid.NewV4()), PassportSeries: 1234, PassportNumber: 567890, EnrichmentStatus: EnrichmentPending, Version: 1}
+	db.users[user.ID] = user
+
+	// the lease is over right away, so a stale claim would be picked up by the next run
+	cfg := EnrichmentConfig{BatchSize: 10, MaxAttempts: 3}
+
+	enrichments, err := s.repo.ClaimEnrichments(ctx, time.Now(), cfg.Lease, cfg.BatchSize)
+	if err != nil || len(enrichments) != 1 {
+		t.Fatalf("claim enrichments: %v, %v", enrichments, err)
+	}
+
+	surname, address := "Petrov", "Kazan"
+	_, err = s.UpdateUser(ctx, UpdateUser{ID: user.ID, Surname: &surname, Address: &address}, nil)
+	if err != nil {
+		t.Fatalf("update user: %v", err)
+	}
+
+	err = s.enrichUser(ctx, cfg, enrichments[0])
+	if err != nil {
+		t.Fatalf("enrich user: %v", err)
+	}
+
+	err = s.enrichPending(ctx, cfg)
+	if err != nil {
+		t.Fatalf("enrich pending: %v", err)
+	}
+
+	got := db.users[user.ID]
+	if got.Surname != surname || got.Name != "" || got.Address != address {
+		t.Errorf("user surname %q, name %q, address %q, want %q, %q, %q", got.Surname, got.Name, got.Address, surname, "", address)
+	}
+	if got.EnrichmentStatus != EnrichmentDone {
+		t.Errorf("enrichment status = %q, want %q", got.EnrichmentStatus, EnrichmentDone)
+	}
+	if calls != 1 {
+		t.Errorf("person info requested %d times, want 1", calls)
+	}
+	if len(db.revisions) != 1 || db.revisions[0].Actor == ActorEnrichment {
+		t.Errorf("got revisions %v, want only the edit", db.revisions)
+	}
+}
+
+func TestEnrichUserRetryDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		want     time.Duration
+	}{
+		{"first failure", 0, time.Minute},
+		{"third failure", 2, 4 * time.Minute},
+		{"capped", 6, time.Hour},
+		{"capped past overflow", 40, time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB(t)
+			s, ctx := newTestService(t, db, personInfoFunc(func(context.Context, int, int) (PersonInfo, error) {
+				return PersonInfo{}, errors.New("unavailable")
+			}))
+
+			user := User{ID: uuid.Must(uuid.NewV4()), EnrichmentStatus: EnrichmentPending, Version: 1}
+			db.users[user.ID] = user
+
+			cfg := EnrichmentConfig{MaxAttempts: 100, RetryDelay: time.Minute, MaxRetryDelay: time.Hour}
+
+			before := time.Now()
+			err := s.enrichUser(ctx, cfg, Enrichment{UserID: user.ID, Attempts: tt.attempts, Version: user.Version})
+			if err != nil {
+				t.Fatalf("enrich user: %v", err)
+			}
+
+			if got := db.users[user.ID].EnrichmentStatus; got != EnrichmentPending {
+				t.Fatalf("enrichment status = %q, want %q", got, EnrichmentPending)
+			}
+			if delay := db.claimedUntil[user.ID].Sub(before); delay < tt.want || delay > tt.want+time.Second {
+				t.Errorf("next attempt in %s, want %s", delay, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateUserEnrichedByProvider(t *testing.T) {
+	fixture := filepath.Join(t.TempDir(), "people.json")
+
+	err := os.WriteFile(fixture, []byte(`{"1234 567890": {"surname": "Ivanov", "name": "Ivan", "patronymic": "Ivanovich", "address": "Moscow, Lenina 5"}}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	static, err := NewStaticPersonInfoProvider(fixture)
+	if err != nil {
+		t.Fatalf("new static provider: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		provider      PersonInfoProvider
+		want          PersonInfo
+		wantRevisions int
+	}{
+		{
+			name:          "static",
+			provider:      static,
+			want:          PersonInfo{Surname: "Ivanov", Name: "Ivan", Patronymic: "Ivanovich", Address: "Moscow, Lenina 5"},
+			wantRevisions: 1,
+		},
+		{
+			name:     "none",
+			provider: NonePersonInfoProvider{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB(t)
+			s, ctx := newTestService(t, db, tt.provider)
+
+			user, err := s.CreateUser(ctx, 1234, 567890)
+			if err != nil {
+				t.Fatalf("create user: %v", err)
+			}
+
+			if user.EnrichmentStatus != EnrichmentPending {
+				t.Errorf("created user enrichment status = %q, want %q", user.EnrichmentStatus, EnrichmentPending)
+			}
+
+			err = s.enrichUser(ctx, EnrichmentConfig{MaxAttempts: 3}, Enrichment{
+				UserID:         user.ID,
+				PassportSeries: user.PassportSeries,
+				PassportNumber: user.PassportNumber,
+				Version:        user.Version,
+			})
+			if err != nil {
+				t.Fatalf("enrich user: %v", err)
+			}
+
+			user, err = s.UserByID(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("get user: %v", err)
+			}
+
+			got := PersonInfo{Surname: user.Surname, Name: user.Name, Patronymic: user.Patronymic, Address: user.Address}
+			if got != tt.want {
+				t.Errorf("saved person info = %+v, want %+v", got, tt.want)
+			}
+			if user.EnrichmentStatus != EnrichmentDone {
+				t.Errorf("enrichment status = %q, want %q", user.EnrichmentStatus, EnrichmentDone)
+			}
+			if len(db.revisions) != tt.wantRevisions {
+				t.Errorf("got %d revisions, want %d", len(db.revisions), tt.wantRevisions)
+			}
+		})
+	}
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return loc
+}
+
+func mustParseTime(t *testing.T, s string) time.Time {
+	t.Helper()
+
+	tm, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tm
+}
+
+func TestStartOfDay(t *testing.T) {
+	tests := []struct {
+		name string
+		loc  string
+		date string
+		want string
+	}{
+		{"utc", "UTC", "2024-03-31T15:00:00Z", "2024-03-31T00:00:00Z"},
+		{"berlin spring forward", "Europe/Berlin", "2024-03-31T15:00:00Z", "2024-03-31T00:00:00+01:00"},
+		{"berlin fall back", "Europe/Berlin", "2024-10-27T15:00:00Z", "2024-10-27T00:00:00+02:00"},
+		{"sao paulo skipped midnight", "America/Sao_Paulo", "2018-11-04T15:00:00Z", "2018-11-04T01:00:00-02:00"},
+		{"sao paulo day before", "America/Sao_Paulo", "2018-11-03T15:00:00Z", "2018-11-03T00:00:00-03:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := startOfDay(mustParseTime(t, tt.date), mustLoadLocation(t, tt.loc))
+			if want := mustParseTime(t, tt.want); !got.Equal(want) {
+				t.Errorf("startOfDay(%s) = %s, want %s", tt.date, got, want)
+			}
+		})
+	}
+}
+
+func TestBucketStartAndNext(t *testing.T) {
+	tests := []struct {
+		name      string
+		loc       string
+		groupBy   string
+		weekStart time.Weekday
+		at        string
+		wantStart string
+		wantNext  string
+	}{
+		{"day spring forward is 23h", "Europe/Berlin", GroupByDay, time.Monday, "2024-03-31T18:00:00+02:00", "2024-03-31T00:00:00+01:00", "2024-04-01T00:00:00+02:00"},
+		{"day fall back is 25h", "Europe/Berlin", GroupByDay, time.Monday, "2024-10-27T18:00:00+01:00", "2024-10-27T00:00:00+02:00", "2024-10-28T00:00:00+01:00"},
+		{"day without midnight", "America/Sao_Paulo", GroupByDay, time.Monday, "2018-11-04T12:00:00-02:00", "2018-11-04T01:00:00-02:00", "2018-11-05T00:00:00-02:00"},
+		{"day before skipped midnight", "America/Sao_Paulo", GroupByDay, time.Monday, "2018-11-03T12:00:00-03:00", "2018-11-03T00:00:00-03:00", "2018-11-04T01:00:00-02:00"},
+		{"week across spring forward", "Europe/Berlin", GroupByWeek, time.Monday, "2024-03-31T12:00:00+02:00", "2024-03-25T00:00:00+01:00", "2024-04-01T00:00:00+02:00"},
+		{"week starting on sunday", "Europe/Berlin", GroupByWeek, time.Sunday, "2024-10-30T12:00:00+01:00", "2024-10-27T00:00:00+02:00", "2024-11-03T00:00:00+01:00"},
+		{"week starting on saturday", "Europe/Berlin", GroupByWeek, time.Saturday, "2024-10-25T12:00:00+02:00", "2024-10-19T00:00:00+02:00", "2024-10-26T00:00:00+02:00"},
+		{"week starting on a skipped midnight", "America/Sao_Paulo", GroupByWeek, time.Sunday, "2018-11-07T12:00:00-02:00", "2018-11-04T01:00:00-02:00", "2018-11-11T00:00:00-02:00"},
+		{"week before a skipped midnight", "America/Sao_Paulo", GroupByWeek, time.Monday, "2018-11-04T12:00:00-02:00", "2018-10-29T00:00:00-03:00", "2018-11-05T00:00:00-02:00"},
+		{"month across fall back", "Europe/Berlin", GroupByMonth, time.Monday, "2024-10-31T23:30:00+01:00", "2024-10-01T00:00:00+02:00", "2024-11-01T00:00:00+01:00"},
+		{"month with a skipped midnight", "America/Sao_Paulo", GroupByMonth, time.Monday, "2018-11-04T12:00:00-02:00", "2018-11-01T00:00:00-03:00", "2018-12-01T00:00:00-02:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := mustParseTime(t, tt.at).In(mustLoadLocation(t, tt.loc))
+
+			start := bucketStart(at, tt.groupBy, tt.weekStart)
+			if want := mustParseTime(t, tt.wantStart); !start.Equal(want) {
+				t.Errorf("bucketStart(%s) = %s, want %s", tt.at, start, want)
+			}
+
+			next := nextBucket(tt.groupBy)(start)
+			if want := mustParseTime(t, tt.wantNext); !next.Equal(want) {
+				t.Errorf("nextBucket(%s) = %s, want %s", start, next, want)
+			}
+		})
+	}
+}
+
+func TestReportPeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		userTZ    string
+		tz        string
+		startDate string
+		endDate   string
+		wantStart string
+		wantEnd   string
+	}{
+		{"user time zone", "Europe/Berlin", "", "2024-03-01", "2024-03-31", "2024-03-01T00:00:00+01:00", "2024-04-01T00:00:00+02:00"},
+		{"tz overrides user time zone", "Europe/Berlin", "UTC", "2024-03-01", "2024-03-31", "2024-03-01T00:00:00Z", "2024-04-01T00:00:00Z"},
+		{"spring forward day", "Europe/Berlin", "", "2024-03-31", "2024-03-31", "2024-03-31T00:00:00+01:00", "2024-04-01T00:00:00+02:00"},
+		{"fall back day", "Europe/Berlin", "", "2024-10-27", "2024-10-27", "2024-10-27T00:00:00+02:00", "2024-10-28T00:00:00+01:00"},
+		{"day without midnight", "UTC", "America/Sao_Paulo", "2018-11-04", "2018-11-04", "2018-11-04T01:00:00-02:00", "2018-11-05T00:00:00-02:00"},
+		{"ends before a day without midnight", "America/Sao_Paulo", "", "2018-11-03", "2018-11-03", "2018-11-03T00:00:00-03:00", "2018-11-04T01:00:00-02:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB(t)
+			s, ctx := newTestService(t, db, NonePersonInfoProvider{})
+
+			user := User{ID: uuid.Must(uuid.NewV4()), TimeZone: tt.userTZ}
+			db.users[user.ID] = user
+
+			var loc *time.Location
+			if tt.tz != "" {
+				loc = mustLoadLocation(t, tt.tz)
+			}
+
+			startDate, _ := time.Parse(time.DateOnly, tt.startDate)
+			endDate, _ := time.Parse(time.DateOnly, tt.endDate)
+
+			period, err := s.ReportPeriod(ctx, user.ID, startDate, endDate, loc, false)
+			if err != nil {
+				t.Fatalf("report period: %v", err)
+			}
+
+			if want := mustParseTime(t, tt.wantStart); !period.StartDate.Equal(want) {
+				t.Errorf("start = %s, want %s", period.StartDate, want)
+			}
+			if want := mustParseTime(t, tt.wantEnd); !period.EndDate.Equal(want) {
+				t.Errorf("end = %s, want %s", period.EndDate, want)
+			}
+		})
+	}
+}
+
+func TestReportPeriodUnknownUser(t *testing.T) {
+	for _, loc := range []*time.Location{nil, time.UTC} {
+		s, ctx := newTestService(t, newFakeDB(t), NonePersonInfoProvider{})
+
+		_, err := s.ReportPeriod(ctx, uuid.Must(uuid.NewV4()), time.Time{}, time.Time{}, loc, false)
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("report period with location %v: error = %v, want %v", loc, err, ErrNotFound)
+		}
+	}
+}
+
+func TestStartWorkUnknownUser(t *testing.T) {
+	s, ctx := newTestService(t, newFakeDB(t), NonePersonInfoProvider{})
+
+	_, err := s.StartWork(ctx, uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()))
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("start work error = %v, want %v", err, ErrNotFound)
+	}
+}
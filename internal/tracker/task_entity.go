@@ -0,0 +1,33 @@
+package tracker
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type Task struct {
+	ID          uuid.UUID  `json:"id"`
+	ProjectID   uuid.UUID  `json:"project_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ClosedAt    *time.Time `json:"closed_at"`
+}
+
+type CreateTask struct {
+	ProjectID   uuid.UUID `json:"project_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+}
+
+type UpdateTask struct {
+	ProjectID   *uuid.UUID `json:"project_id"`
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	Closed      *bool      `json:"closed"`
+}
+
+type TaskFilter struct {
+	ProjectID *uuid.UUID
+}
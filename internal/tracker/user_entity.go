@@ -6,17 +6,110 @@ import (
 	"github.com/gofrs/uuid"
 )
 
+const (
+	EnrichmentPending = "pending"
+	EnrichmentDone    = "done"
+	EnrichmentFailed  = "failed"
+)
+
 type User struct {
-	ID             uuid.UUID `json:"id"`
-	PassportSeries int       `json:"passport_series"`
-	PassportNumber int       `json:"passport_number"`
-	Surname        string    `json:"surname"`
-	Name           string    `json:"name"`
-	Patronymic     string    `json:"patronymic"`
-	Address        string    `json:"address"`
-	CreatedAt      time.Time `json:"created_at"`
+	ID               uuid.UUID  `json:"id"`
+	PassportSeries   int        `json:"passport_series"`
+	PassportNumber   int        `json:"passport_number"`
+	Surname          string     `json:"surname"`
+	Name             string     `json:"name"`
+	Patronymic       string     `json:"patronymic"`
+	Address          string     `json:"address"`
+	TimeZone         string     `json:"time_zone"`
+	EnrichmentStatus string     `json:"enrichment_status"`
+	EnrichmentError  string     `json:"enrichment_error"`
+	Version          int        `json:"version"`
+	CreatedAt        time.Time  `json:"created_at"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+}
+
+const (
+	SortBySurname   = "surname"
+	SortByName      = "name"
+	SortByCreatedAt = "created_at"
+	SortByPassport  = "passport"
+)
+
+type UserSort struct {
+	Field string
+	Desc  bool
+}
+
+type UserPage struct {
+	Page    int
+	PerPage int
+	Sort    []UserSort
+	// After switches to keyset pagination, Page is ignored then.
+	After *UserCursor
+}
+
+// UserCursor points at the last user of a page. Values hold the sort
+// columns of that user in the order of Sort.
+type UserCursor struct {
+	Sort   string    `json:"sort"`
+	Values []string  `json:"values"`
+	ID     uuid.UUID `json:"id"`
+}
+
+type UsersPage struct {
+	Users      []User
+	Total      int
+	NextCursor *UserCursor
 }
 
+// ImportUser is a validated row of a bulk import. Users with a pre-filled
+// surname and name are stored as enriched.
+type ImportUser struct {
+	Row            int
+	PassportSeries int
+	PassportNumber int
+	Surname        string
+	Name           string
+	Patronymic     string
+	Address        string
+}
+
+type ImportUserResult struct {
+	Row   int        `json:"row"`
+	ID    *uuid.UUID `json:"id,omitempty"`
+	Error string     `json:"error,omitempty"`
+}
+
+type ImportUsersReport struct {
+	Created int                `json:"created"`
+	Failed  int                `json:"failed"`
+	Rows    []ImportUserResult `json:"rows"`
+}
+
+// Enrichment is a user claimed by the enrichment worker.
+type Enrichment struct {
+	UserID         uuid.UUID
+	PassportSeries int
+	PassportNumber int
+	Attempts       int
+	// Version is the user version when claimed, the result is saved only if
+	// the user hasn't changed since.
+	Version int
+}
+
+type EnrichmentConfig struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	RetryDelay  time.Duration
+	// MaxRetryDelay caps RetryDelay, which doubles with every failed attempt.
+	MaxRetryDelay time.Duration
+	// Lease is how long a claimed user is hidden from other workers.
+	Lease time.Duration
+}
+
+// UpdateUser is the body of PATCH /users/{user_id}. ID is taken from the path
+// and is only read from the body by the deprecated PATCH /users.
 type UpdateUser struct {
 	ID             uuid.UUID `json:"id"`
 	PassportSeries *int      `json:"passport_series"`
@@ -25,8 +118,16 @@ type UpdateUser struct {
 	Name           *string   `json:"name"`
 	Patronymic     *string   `json:"patronymic"`
 	Address        *string   `json:"address"`
+	// TimeZone is an IANA name like "Asia/Vladivostok".
+	TimeZone *string `json:"time_zone"`
 }
 
+const (
+	MatchExact    = "exact"
+	MatchPrefix   = "prefix"
+	MatchContains = "contains"
+)
+
 type UserFilter struct {
 	ID             *uuid.UUID
 	PassportSeries *int
@@ -35,4 +136,10 @@ type UserFilter struct {
 	Name           *string
 	Patronymic     *string
 	Address        *string
+	// Match and IgnoreCase apply to surname, name, patronymic and address.
+	Match      string
+	IgnoreCase bool
+	// Query is a free text search across surname, name, patronymic and address.
+	Query          string
+	IncludeDeleted bool
 }
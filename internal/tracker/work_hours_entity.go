@@ -7,20 +7,136 @@ import (
 )
 
 type WorkHours struct {
+	ID           uuid.UUID  `json:"id"`
 	UserID       uuid.UUID  `json:"user_id"`
 	TaskID       uuid.UUID  `json:"task_id"`
 	StartedAt    time.Time  `json:"started_at"`
 	FinishedAt   *time.Time `json:"finished_at"`
 	SpendTimeSec int        `json:"spend_time_sec"`
+	// AutoClosed marks sessions closed by the reaper, they need a review.
+	AutoClosed bool `json:"auto_closed"`
+}
+
+type ReaperConfig struct {
+	Interval  time.Duration
+	BatchSize int
+	// MaxOpen is how long a session may run, the reaper finishes it at
+	// started_at + MaxOpen.
+	MaxOpen time.Duration
+}
+
+// WorkEntryFilter narrows the work entries list, nil fields don't filter.
+// From and To select entries overlapping [From, To).
+type WorkEntryFilter struct {
+	UserID         *uuid.UUID
+	TaskID         *uuid.UUID
+	From           *time.Time
+	To             *time.Time
+	Open           *bool
+	MinDurationSec *int
+	AutoClosed     *bool
+}
+
+type WorkEntryPage struct {
+	Page    int
+	PerPage int
+}
+
+type WorkEntriesPage struct {
+	Entries []WorkHours
+	Total   int
+}
+
+// CreateWorkEntry is a manually logged past interval of work.
+type CreateWorkEntry struct {
+	UserID     uuid.UUID `json:"user_id"`
+	TaskID     uuid.UUID `json:"task_id"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+type UpdateWorkEntry struct {
+	TaskID     *uuid.UUID `json:"task_id"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+// ActiveWork is a running session with the time worked so far, pauses excluded.
+type ActiveWork struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"user_id"`
+	Surname    string    `json:"surname"`
+	Name       string    `json:"name"`
+	Patronymic string    `json:"patronymic"`
+	TaskID     uuid.UUID `json:"task_id"`
+	TaskTitle  string    `json:"task_title"`
+	StartedAt  time.Time `json:"started_at"`
+	Paused     bool      `json:"paused"`
+	ElapsedSec int       `json:"elapsed_sec"`
+}
+
+// SwitchWork is the result of switching a user to another task.
+type SwitchWork struct {
+	Finished []WorkHours `json:"finished"`
+	Started  WorkHours   `json:"started"`
+}
+
+// WorkPause is an interval when a running session was paused, it is open
+// until ResumedAt is set.
+type WorkPause struct {
+	ID          uuid.UUID  `json:"id"`
+	WorkHoursID uuid.UUID  `json:"work_hours_id"`
+	PausedAt    time.Time  `json:"paused_at"`
+	ResumedAt   *time.Time `json:"resumed_at"`
 }
 
 type TaskSpendTime struct {
 	UserID       uuid.UUID `json:"user_id"`
 	TaskID       uuid.UUID `json:"task_id"`
+	TaskTitle    string    `json:"task_title"`
+	ProjectID    uuid.UUID `json:"project_id"`
+	ProjectName  string    `json:"project_name"`
+	ClientID     uuid.UUID `json:"client_id"`
+	ClientName   string    `json:"client_name"`
+	SpendTimeSec int       `json:"spend_time_sec"`
+}
+
+type ProjectSpendTime struct {
+	UserID       uuid.UUID `json:"user_id"`
+	ProjectID    uuid.UUID `json:"project_id"`
+	ProjectName  string    `json:"project_name"`
+	ClientID     uuid.UUID `json:"client_id"`
+	ClientName   string    `json:"client_name"`
 	SpendTimeSec int       `json:"spend_time_sec"`
 }
 
+type ClientSpendTime struct {
+	UserID       uuid.UUID `json:"user_id"`
+	ClientID     uuid.UUID `json:"client_id"`
+	ClientName   string    `json:"client_name"`
+	SpendTimeSec int       `json:"spend_time_sec"`
+}
+
+const (
+	GroupByDay   = "day"
+	GroupByWeek  = "week"
+	GroupByMonth = "month"
+)
+
+// ReportBucket is a day, week or month of a report clipped to its period.
+type ReportBucket struct {
+	Start        time.Time       `json:"start"`
+	End          time.Time       `json:"end"`
+	SpendTimeSec int             `json:"spend_time_sec"`
+	Tasks        []TaskSpendTime `json:"tasks"`
+}
+
+// Period is [StartDate, EndDate) with day boundaries in Location. Reports
+// count sessions clipped to the period, running ones up to RunningUntil
+// unless it's nil.
 type Period struct {
-	StartDate time.Time
-	EndDate   time.Time
+	StartDate    time.Time
+	EndDate      time.Time
+	Location     *time.Location
+	RunningUntil *time.Time
 }
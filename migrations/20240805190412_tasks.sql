@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE tasks (
+    id UUID PRIMARY KEY,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    closed_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+-- tasks that were tracked before the table existed get a placeholder title
+INSERT INTO tasks (id, title, created_at)
+SELECT task_id, task_id::TEXT, MIN(started_at)
+FROM work_hours
+GROUP BY task_id;
+
+ALTER TABLE work_hours ADD CONSTRAINT work_hours_task_id_fkey FOREIGN KEY (task_id) REFERENCES tasks (id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE work_hours DROP CONSTRAINT work_hours_task_id_fkey;
+DROP TABLE tasks;
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE clients (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE projects (
+    id UUID PRIMARY KEY,
+    client_id UUID NOT NULL REFERENCES clients (id),
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    deleted_at TIMESTAMPTZ
+);
+
+ALTER TABLE tasks ADD COLUMN project_id UUID REFERENCES projects (id);
+
+-- tasks created before projects existed are moved to a placeholder project
+DO $$
+DECLARE
+    default_client_id UUID := gen_random_uuid();
+    default_project_id UUID := gen_random_uuid();
+BEGIN
+    IF EXISTS (SELECT 1 FROM tasks) THEN
+        INSERT INTO clients (id, name, created_at) VALUES (default_client_id, 'Unassigned', now());
+        INSERT INTO projects (id, client_id, name, created_at) VALUES (default_project_id, default_client_id, 'Unassigned', now());
+        UPDATE tasks SET project_id = default_project_id;
+    END IF;
+END $$;
+
+ALTER TABLE tasks ALTER COLUMN project_id SET NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tasks DROP COLUMN project_id;
+DROP TABLE projects;
+DROP TABLE clients;
+-- +goose StatementEnd
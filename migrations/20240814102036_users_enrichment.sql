@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN enrichment_status TEXT NOT NULL DEFAULT 'done',
+    ADD COLUMN enrichment_error TEXT NOT NULL DEFAULT '',
+    ADD COLUMN enrichment_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN enrichment_next_at TIMESTAMPTZ;
+
+CREATE INDEX users_enrichment_pending_idx ON users (enrichment_next_at) WHERE enrichment_status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX users_enrichment_pending_idx;
+
+ALTER TABLE users
+    DROP COLUMN enrichment_status,
+    DROP COLUMN enrichment_error,
+    DROP COLUMN enrichment_attempts,
+    DROP COLUMN enrichment_next_at;
+-- +goose StatementEnd
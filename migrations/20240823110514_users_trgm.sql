@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX users_surname_trgm_idx ON users USING gin (surname gin_trgm_ops);
+CREATE INDEX users_name_trgm_idx ON users USING gin (name gin_trgm_ops);
+CREATE INDEX users_patronymic_trgm_idx ON users USING gin (patronymic gin_trgm_ops);
+CREATE INDEX users_address_trgm_idx ON users USING gin (address gin_trgm_ops);
+
+-- must match the expression used by the q parameter of GET /users
+CREATE INDEX users_search_trgm_idx ON users USING gin ((surname || ' ' || name || ' ' || patronymic || ' ' || address) gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX users_search_trgm_idx;
+DROP INDEX users_address_trgm_idx;
+DROP INDEX users_patronymic_trgm_idx;
+DROP INDEX users_name_trgm_idx;
+DROP INDEX users_surname_trgm_idx;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- keep the earliest of duplicated users, the rest are soft-deleted with a
+-- revision in their history and can be looked up with include_deleted
+WITH deleted AS (
+    UPDATE users SET deleted_at = now()
+    WHERE deleted_at ISNULL AND id IN (
+        SELECT id FROM (
+            SELECT id, row_number() OVER (PARTITION BY passport_series, passport_number ORDER BY created_at, id) AS n
+            FROM users WHERE deleted_at ISNULL
+        ) d
+        WHERE d.n > 1
+    )
+    RETURNING id, deleted_at
+)
+INSERT INTO user_revisions (id, user_id, action, changes, actor, created_at)
+SELECT gen_random_uuid(), id, 'delete',
+       jsonb_build_object('deleted_at', jsonb_build_object('old', NULL, 'new', deleted_at)),
+       'migration', deleted_at
+FROM deleted;
+
+CREATE UNIQUE INDEX users_passport_uniq_idx ON users (passport_series, passport_number) WHERE deleted_at ISNULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX users_passport_uniq_idx;
+-- +goose StatementEnd
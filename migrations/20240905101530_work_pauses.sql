@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE work_pauses (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id),
+    task_id UUID NOT NULL REFERENCES tasks (id),
+    paused_at TIMESTAMPTZ NOT NULL,
+    resumed_at TIMESTAMPTZ
+);
+
+CREATE INDEX work_pauses_user_id_task_id_idx ON work_pauses (user_id, task_id, paused_at);
+CREATE UNIQUE INDEX work_pauses_open_uniq_idx ON work_pauses (user_id, task_id) WHERE resumed_at ISNULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE work_pauses;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE work_hours ADD COLUMN id UUID;
+
+UPDATE work_hours SET id = gen_random_uuid();
+
+ALTER TABLE work_hours ALTER COLUMN id SET NOT NULL;
+ALTER TABLE work_hours ADD PRIMARY KEY (id);
+
+CREATE INDEX work_hours_user_id_started_at_idx ON work_hours (user_id, started_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX work_hours_user_id_started_at_idx;
+ALTER TABLE work_hours DROP COLUMN id;
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE work_pauses ADD COLUMN work_hours_id UUID REFERENCES work_hours (id);
+
+-- a pause belongs to the session of the same user and task that was running when it started
+UPDATE work_pauses wp
+SET work_hours_id = wh.id
+FROM work_hours wh
+WHERE wh.user_id = wp.user_id AND wh.task_id = wp.task_id
+    AND wp.paused_at >= wh.started_at AND (wh.finished_at ISNULL OR wp.paused_at < wh.finished_at);
+
+DELETE FROM work_pauses WHERE work_hours_id ISNULL;
+
+ALTER TABLE work_pauses ALTER COLUMN work_hours_id SET NOT NULL;
+
+DROP INDEX work_pauses_open_uniq_idx;
+DROP INDEX work_pauses_user_id_task_id_idx;
+ALTER TABLE work_pauses DROP COLUMN user_id, DROP COLUMN task_id;
+
+CREATE INDEX work_pauses_work_hours_id_idx ON work_pauses (work_hours_id, paused_at);
+CREATE UNIQUE INDEX work_pauses_open_uniq_idx ON work_pauses (work_hours_id) WHERE resumed_at ISNULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE work_pauses ADD COLUMN user_id UUID REFERENCES users (id), ADD COLUMN task_id UUID REFERENCES tasks (id);
+
+UPDATE work_pauses wp
+SET user_id = wh.user_id, task_id = wh.task_id
+FROM work_hours wh
+WHERE wh.id = wp.work_hours_id;
+
+ALTER TABLE work_pauses ALTER COLUMN user_id SET NOT NULL, ALTER COLUMN task_id SET NOT NULL;
+
+DROP INDEX work_pauses_open_uniq_idx;
+DROP INDEX work_pauses_work_hours_id_idx;
+ALTER TABLE work_pauses DROP COLUMN work_hours_id;
+
+CREATE INDEX work_pauses_user_id_task_id_idx ON work_pauses (user_id, task_id, paused_at);
+CREATE UNIQUE INDEX work_pauses_open_uniq_idx ON work_pauses (user_id, task_id) WHERE resumed_at ISNULL;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- of concurrently opened duplicates the earliest session stays open, the rest are closed empty
+WITH duplicates AS (
+    SELECT id
+    FROM (
+        SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, task_id ORDER BY started_at, id) rn
+        FROM work_hours
+        WHERE finished_at ISNULL
+    ) open_work
+    WHERE rn > 1
+), closed_pauses AS (
+    UPDATE work_pauses SET resumed_at = paused_at
+    WHERE resumed_at ISNULL AND work_hours_id IN (SELECT id FROM duplicates)
+)
+UPDATE work_hours SET finished_at = started_at, spend_time_sec = 0
+WHERE id IN (SELECT id FROM duplicates);
+
+CREATE UNIQUE INDEX work_hours_open_uniq_idx ON work_hours (user_id, task_id) WHERE finished_at ISNULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX work_hours_open_uniq_idx;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE work_hours ADD COLUMN auto_closed BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX work_hours_open_started_at_idx ON work_hours (started_at) WHERE finished_at ISNULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX work_hours_open_started_at_idx;
+ALTER TABLE work_hours DROP COLUMN auto_closed;
+-- +goose StatementEnd